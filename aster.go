@@ -1,22 +1,162 @@
 package aster
 
 import (
+	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 )
 
-func ParseDir(dirPath string, fileFilter func(os.FileInfo) bool) ([]*PackageType, error) {
-	var err error
-	fSet := token.NewFileSet()
-	pkgs, err := parser.ParseDir(fSet, dirPath, fileFilter, parser.ParseComments)
+// opts可以省略，省略时使用默认的选项
+func ParseDir(dirPath string, fileFilter func(os.FileInfo) bool, opts ...*ParseOptions) ([]*PackageType, error) {
+	opt := getParseOptions(opts)
+	fileInfos, err := readDir(dirPath, opt)
+	if err != nil {
+		return nil, err
+	}
+	filePaths := make([]string, 0, len(fileInfos))
+	ignoredPaths := make([]string, 0, 4)
+	for _, fileInfo := range fileInfos {
+		if fileFilter != nil && !fileFilter(fileInfo) {
+			continue
+		}
+		if opt.skipTestFile(fileInfo.Name()) {
+			continue
+		}
+		filePath := filepath.Join(dirPath, fileInfo.Name())
+		if opt.matchBuildConstraint(dirPath, fileInfo.Name()) {
+			filePaths = append(filePaths, filePath)
+		} else {
+			ignoredPaths = append(ignoredPaths, filePath)
+		}
+	}
+	pkgsTyp, err := parseFiles(opt, dirPath, filePaths)
+	if err != nil {
+		return nil, err
+	}
+	pkgsTyp, err = addIgnoredFiles(pkgsTyp, dirPath, ignoredPaths, opt)
+	if err != nil {
+		return nil, err
+	}
+	linkXTests(pkgsTyp)
+	return pkgsTyp, nil
+}
+
+// 解析内存中的源码，filename用于记录位置以及推断是否是_test.go文件等，不要求在磁盘上存在
+func ParseSource(filename string, src []byte, opts ...*ParseOptions) (*PackageType, error) {
+	// 复制一份选项，避免修改调用方的Overlay
+	opt := *getParseOptions(opts)
+	overlay := make(map[string][]byte, len(opt.Overlay)+1)
+	for overlayPath, overlaySrc := range opt.Overlay {
+		overlay[overlayPath] = overlaySrc
+	}
+	overlay[filename] = src
+	opt.Overlay = overlay
+	return ParseFile(filename, &opt)
+}
+
+// 列出目录下所有的.go文件，包括只存在于Overlay中的文件，按文件名排序
+func readDir(dirPath string, opt *ParseOptions) ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil && !(os.IsNotExist(err) && len(opt.getOverlayFileNames(dirPath)) > 0) {
+		return nil, err
+	}
+	fileInfos := make([]os.FileInfo, 0, len(entries))
+	fileNameSet := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		fileInfos = append(fileInfos, fileInfo)
+		fileNameSet[entry.Name()] = struct{}{}
+	}
+	for _, fileName := range opt.getOverlayFileNames(dirPath) {
+		if _, ok := fileNameSet[fileName]; ok || !strings.HasSuffix(fileName, ".go") {
+			continue
+		}
+		src, _ := opt.getOverlay(filepath.Join(dirPath, fileName))
+		fileInfos = append(fileInfos, &overlayFileInfo{name: fileName, size: int64(len(src))})
+	}
+	sort.Slice(fileInfos, func(i, j int) bool {
+		return fileInfos[i].Name() < fileInfos[j].Name()
+	})
+	return fileInfos, nil
+}
+
+// 与ParseDir使用相同的流程，只是只解析一个文件
+// 文件被选项排除时（例如设置了SkipTests时解析_test.go文件）返回错误
+func ParseFile(filePath string, opts ...*ParseOptions) (*PackageType, error) {
+	opt := getParseOptions(opts)
+	if opt.skipTestFile(filePath) || !opt.matchBuildConstraint(filepath.Dir(filePath), filepath.Base(filePath)) {
+		return nil, fmt.Errorf("ParseFile()文件%s被选项排除", filePath)
+	}
+	pkgsTyp, err := parseFiles(opt, filepath.Dir(filePath), []string{filePath})
 	if err != nil {
 		return nil, err
 	}
+	return pkgsTyp[0], nil
+}
+
+// 解析dirPath中的文件，并按包名分组，返回的包按包名排序，例如`model`总是排在`model_test`前面
+func parseFiles(opt *ParseOptions, dirPath string, filePaths []string) ([]*PackageType, error) {
+	fSet := opt.getFileSet()
+	pkgs := make(map[string]*ast.Package)
+	for _, filePath := range filePaths {
+		// src为nil时parser会从磁盘读取
+		var src interface{}
+		if overlaySrc, ok := opt.getOverlay(filePath); ok {
+			src = overlaySrc
+		}
+		astFile, err := parser.ParseFile(fSet, filePath, src, opt.getMode())
+		if err != nil {
+			return nil, err
+		}
+		opt.trimFile(astFile)
+		pkgName := astFile.Name.Name
+		astPkg, ok := pkgs[pkgName]
+		if !ok {
+			astPkg = &ast.Package{
+				Name:  pkgName,
+				Files: make(map[string]*ast.File),
+			}
+			pkgs[pkgName] = astPkg
+		}
+		astPkg.Files[filePath] = astFile
+	}
+	pkgsTyp, err := newPackageTypes(fSet, pkgs)
+	if err != nil {
+		return nil, err
+	}
+	if err := setPackageDir(pkgsTyp, dirPath); err != nil {
+		return nil, err
+	}
+	if opt.TypeCheck {
+		// 同一次解析中的包共用importer，避免重复检查依赖的包
+		typesImporter := newTypesImporter(opt, fSet, dirPath)
+		for _, pkgTyp := range pkgsTyp {
+			pkgTyp.typeCheck(typesImporter)
+		}
+	}
+	return pkgsTyp, nil
+}
+
+func newPackageTypes(fSet *token.FileSet, pkgs map[string]*ast.Package) ([]*PackageType, error) {
+	pkgNames := make([]string, 0, len(pkgs))
+	for pkgName := range pkgs {
+		pkgNames = append(pkgNames, pkgName)
+	}
+	sort.Strings(pkgNames)
 	pkgsTyp := make([]*PackageType, 0, len(pkgs))
-	for _, astPkg := range pkgs {
-		pkgTyp, err := NewPackageType(astPkg)
+	for _, pkgName := range pkgNames {
+		pkgTyp, err := NewPackageTypeWithFileSet(fSet, pkgs[pkgName])
 		if err != nil {
 			return nil, err
 		}
@@ -25,21 +165,78 @@ func ParseDir(dirPath string, fileFilter func(os.FileInfo) bool) ([]*PackageType
 	return pkgsTyp, nil
 }
 
-func ParseFile(filePath string) (*PackageType, error) {
+// 按package声明把不满足构建约束的文件归入对应的包，无法解析package声明的文件会被丢弃
+// 目录中所有的文件都被排除时，为每个package声明创建一个只有IgnoredFiles的空包；
+// 否则没有对应的包的文件归入第一个非外部测试包，与go/build的IgnoredGoFiles一致
+func addIgnoredFiles(pkgsTyp []*PackageType, dirPath string, ignoredPaths []string, opt *ParseOptions) ([]*PackageType, error) {
+	if len(ignoredPaths) == 0 {
+		return pkgsTyp, nil
+	}
+	pkgMap := make(map[string]*PackageType, len(pkgsTyp))
+	var defaultPkgTyp *PackageType
+	for _, pkgTyp := range pkgsTyp {
+		pkgMap[pkgTyp.Name] = pkgTyp
+		if defaultPkgTyp == nil && !strings.HasSuffix(pkgTyp.Name, "_test") {
+			defaultPkgTyp = pkgTyp
+		}
+	}
+	if defaultPkgTyp == nil && len(pkgsTyp) > 0 {
+		defaultPkgTyp = pkgsTyp[0]
+	}
+	emptyPkgsTyp := make([]*PackageType, 0, 1)
+	// 只用于读取package声明，不需要记录位置
 	fSet := token.NewFileSet()
-	var astPkg *ast.Package
-	if src, err := parser.ParseFile(fSet, filePath, nil, 0); err == nil {
-		astPkg = &ast.Package{
-			Name:  src.Name.Name,
-			Files: make(map[string]*ast.File),
-		}
-		astPkg.Files[filePath] = src
-		pkgTyp, err := NewPackageType(astPkg)
+	for _, filePath := range ignoredPaths {
+		var src interface{}
+		if overlaySrc, ok := opt.getOverlay(filePath); ok {
+			src = overlaySrc
+		}
+		astFile, err := parser.ParseFile(fSet, filePath, src, parser.PackageClauseOnly)
 		if err != nil {
-			return nil, err
+			continue
+		}
+		pkgTyp, ok := pkgMap[astFile.Name.Name]
+		if !ok && defaultPkgTyp != nil {
+			pkgTyp, ok = defaultPkgTyp, true
+		}
+		if !ok {
+			pkgTyp, err = NewPackageTypeWithFileSet(opt.getFileSet(), &ast.Package{
+				Name:  astFile.Name.Name,
+				Files: make(map[string]*ast.File),
+			})
+			if err != nil {
+				return nil, err
+			}
+			pkgMap[pkgTyp.Name] = pkgTyp
+			emptyPkgsTyp = append(emptyPkgsTyp, pkgTyp)
 		}
-		return pkgTyp, nil
-	} else {
+		pkgTyp.IgnoredFiles = append(pkgTyp.IgnoredFiles, filePath)
+	}
+	if len(emptyPkgsTyp) == 0 {
+		return pkgsTyp, nil
+	}
+	if err := setPackageDir(emptyPkgsTyp, dirPath); err != nil {
 		return nil, err
 	}
+	sort.Slice(emptyPkgsTyp, func(i, j int) bool {
+		return emptyPkgsTyp[i].Name < emptyPkgsTyp[j].Name
+	})
+	return emptyPkgsTyp, nil
+}
+
+// 关联同一目录下的包与其外部测试包
+func linkXTests(pkgsTyp []*PackageType) {
+	pkgMap := make(map[string]*PackageType, len(pkgsTyp))
+	for _, pkgTyp := range pkgsTyp {
+		pkgMap[pkgTyp.Name] = pkgTyp
+	}
+	for _, pkgTyp := range pkgsTyp {
+		if !strings.HasSuffix(pkgTyp.Name, "_test") {
+			continue
+		}
+		if testFor, ok := pkgMap[strings.TrimSuffix(pkgTyp.Name, "_test")]; ok {
+			testFor.XTest = pkgTyp
+			pkgTyp.TestFor = testFor
+		}
+	}
 }
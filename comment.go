@@ -1,3 +1,26 @@
 package aster
 
+import "go/ast"
+
 type Comment = string
+
+// 把注释组转换为[]Comment，注释组为nil时返回nil
+func newComments(astCommentGroup *ast.CommentGroup) []Comment {
+	if astCommentGroup == nil {
+		return nil
+	}
+	comments := make([]Comment, 0, len(astCommentGroup.List))
+	for _, comment := range astCommentGroup.List {
+		comments = append(comments, comment.Text)
+	}
+	return comments
+}
+
+// 获取类型或者值声明的注释
+// 优先使用spec自身的注释，只有不在括号内声明时才使用GenDecl的注释
+func newSpecDocs(astGenDecl *ast.GenDecl, specDoc *ast.CommentGroup) []Comment {
+	if specDoc == nil && !astGenDecl.Lparen.IsValid() {
+		specDoc = astGenDecl.Doc
+	}
+	return newComments(specDoc)
+}
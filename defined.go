@@ -0,0 +1,104 @@
+package aster
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// 用于描述一个非struct、非interface的具名类型，形如
+// `type IDs []int64`、`type Handler func(ctx context.Context) error`、`type Status int`
+// 也用于描述所有的类型别名，形如`type StatusID = int8`
+type DefinedType struct {
+	// 当前类型所属的PackageType的引用
+	PackageType *PackageType `json:"-"`
+
+	Name       string        `json:",omitempty"`
+	TypeParams []*FieldType  `json:",omitempty"`
+	Type       *TypeType     `json:",omitempty"` // 底层类型，是别名时则是别名指向的类型
+	IsAlias    bool          `json:",omitempty"` // 是否是以`type A = B`声明的别名
+	Methods    []*MethodType `json:",omitempty"`
+	Docs       []Comment     `json:",omitempty"`
+	Pos        Position      `json:",omitempty"`
+
+	astSpec *ast.TypeSpec
+}
+
+func (pkgType *PackageType) NewDefinedType(astGenDecl *ast.GenDecl, typeSpec *ast.TypeSpec) (*DefinedType, error) {
+	definedType := &DefinedType{
+		PackageType: pkgType,
+
+		Name:    typeSpec.Name.Name,
+		IsAlias: typeSpec.Assign.IsValid(),
+		Methods: make([]*MethodType, 0, 4),
+		astSpec: typeSpec,
+	}
+
+	if typeSpec.TypeParams != nil {
+		definedType.TypeParams = make([]*FieldType, 0, typeSpec.TypeParams.NumFields())
+		for _, astField := range typeSpec.TypeParams.List {
+			fieldTypes, err := NewFieldTypes(astField)
+			if err != nil {
+				return nil, err
+			}
+			definedType.TypeParams = append(definedType.TypeParams, fieldTypes...)
+		}
+	}
+
+	typeType, err := NewTypeType(typeSpec.Type)
+	if err != nil {
+		return nil, err
+	}
+	definedType.Type = typeType
+	definedType.Docs = newSpecDocs(astGenDecl, typeSpec.Doc)
+	return definedType, nil
+}
+
+// 声明时的名字，形如 IDs[T any]
+func (this *DefinedType) GetDeclName() string {
+	if len(this.TypeParams) == 0 {
+		return this.Name
+	}
+	sb := &strings.Builder{}
+	sb.WriteString(this.Name + "[")
+	for i, typeParam := range this.TypeParams {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(typeParam.GetDecl())
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// 作为接受者时的名字，形如 IDs[T]
+func (this *DefinedType) GetRecvName() string {
+	if len(this.TypeParams) == 0 {
+		return this.Name
+	}
+	sb := &strings.Builder{}
+	sb.WriteString(this.Name + "[")
+	for i, typeParam := range this.TypeParams {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(typeParam.Name)
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// 别名指向的类型，不是别名时返回nil
+func (this *DefinedType) GetAliasTarget() *TypeType {
+	if !this.IsAlias {
+		return nil
+	}
+	return this.Type
+}
+
+// 获取完整的类型声明，形如`type IDs []int64`或者`type StatusID = int8`
+func (this *DefinedType) GetDecl() string {
+	if this.IsAlias {
+		return "type " + this.GetDeclName() + " = " + this.Type.GetDecl() + "\n"
+	}
+	return "type " + this.GetDeclName() + " " + this.Type.GetDecl() + "\n"
+}
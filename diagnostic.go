@@ -0,0 +1,24 @@
+package aster
+
+import "fmt"
+
+// 用于描述解析过程中发现的、不影响解析结果的问题
+type Diagnostic struct {
+	Pos     Position `json:",omitempty"`
+	Message string   `json:",omitempty"`
+}
+
+// 形如`model.go:12:2: missing db tag`
+func (this *Diagnostic) String() string {
+	if !this.Pos.IsValid() {
+		return this.Message
+	}
+	return this.Pos.String() + ": " + this.Message
+}
+
+func (this *PackageType) addDiagnostic(pos Position, format string, args ...interface{}) {
+	this.Diagnostics = append(this.Diagnostics, &Diagnostic{
+		Pos:     pos,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
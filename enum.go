@@ -0,0 +1,146 @@
+package aster
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// 用于描述一个枚举，即以具名类型声明的一组常量，形如
+//
+//	type StatusID int8
+//
+//	const (
+//		StatusA StatusID = iota
+//		StatusB
+//	)
+type EnumType struct {
+	PackageType *PackageType `json:"-"`
+
+	Name    string       `json:",omitempty"`
+	Type    *TypeType    `json:",omitempty"` // 枚举的基础类型，例如`int8`
+	Members []*ValueType `json:",omitempty"` // 按声明顺序排列的枚举值
+	Docs    []Comment    `json:",omitempty"`
+	Pos     Position     `json:",omitempty"`
+
+	astSpec *ast.TypeSpec
+}
+
+func (pkgType *PackageType) NewEnumType(astGenDecl *ast.GenDecl, typeSpec *ast.TypeSpec, astIdent *ast.Ident) (*EnumType, error) {
+	typeType, err := NewTypeType(astIdent)
+	if err != nil {
+		return nil, err
+	}
+	enumType := &EnumType{
+		PackageType: pkgType,
+
+		Name:    typeSpec.Name.Name,
+		Type:    typeType,
+		astSpec: typeSpec,
+	}
+
+	enumType.Docs = newSpecDocs(astGenDecl, typeSpec.Doc)
+	return enumType, nil
+}
+
+// 根据名字查找枚举值
+func (this *EnumType) GetMember(name string) (*ValueType, bool) {
+	for _, member := range this.Members {
+		if member.Name == name {
+			return member, true
+		}
+	}
+	return nil, false
+}
+
+// 获取完整的枚举声明
+func (this *EnumType) GetDecl() string {
+	sb := strings.Builder{}
+	for _, doc := range this.Docs {
+		sb.WriteString(doc + "\n")
+	}
+	sb.WriteString("type " + this.Name + " " + this.Type.GetDecl() + "\n\n")
+	sb.WriteString("const (\n")
+	for _, member := range this.Members {
+		for _, doc := range member.Docs {
+			sb.WriteString("\t" + doc + "\n")
+		}
+		sb.WriteString("\t" + member.Name + " " + this.Name)
+		// 无法求值时（例如引用了其他包的常量）使用源码中的表达式
+		if member.ConstValue != nil {
+			sb.WriteString(" = " + member.GetConstValue())
+		} else if member.Value != "" {
+			sb.WriteString(" = " + member.Value)
+		}
+		for _, comment := range member.Comments {
+			sb.WriteString(" " + comment)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(")\n")
+	return sb.String()
+}
+
+// 把类型为枚举的常量按声明顺序归入对应的EnumType
+// 没有任何常量的候选枚举类型会被丢弃
+func (this *PackageType) collectEnums(candidates []*EnumType) {
+	enumMap := make(map[string]*EnumType, len(candidates))
+	for _, enumType := range candidates {
+		enumMap[enumType.Name] = enumType
+	}
+	constMap := make(map[string]*ValueType, len(this.Consts))
+	for _, valueType := range this.Consts {
+		constMap[valueType.Name] = valueType
+	}
+	for _, valueType := range this.Consts {
+		if valueType.Name == "_" {
+			continue
+		}
+		typeName := inferConstTypeName(valueType, constMap, make(map[*ValueType]bool))
+		if enumType, ok := enumMap[typeName]; ok {
+			enumType.Members = append(enumType.Members, valueType)
+		}
+	}
+	for _, enumType := range candidates {
+		if len(enumType.Members) > 0 {
+			this.Enums = append(this.Enums, enumType)
+		}
+	}
+}
+
+// 推断常量的具名类型
+// 省略了类型声明的常量（例如`PermAll = PermRead | PermWrite`）沿用表达式中引用的具名常量的类型
+func inferConstTypeName(valueType *ValueType, constMap map[string]*ValueType, visited map[*ValueType]bool) string {
+	if valueType.Type != nil {
+		if valueType.Type.Kind == Ident {
+			return valueType.Type.Name
+		}
+		return ""
+	}
+	if valueType.astValue == nil || visited[valueType] {
+		return ""
+	}
+	visited[valueType] = true
+
+	typeName := ""
+	ast.Inspect(valueType.astValue, func(node ast.Node) bool {
+		if typeName != "" {
+			return false
+		}
+		switch nodeType := node.(type) {
+		case *ast.CallExpr:
+			// 形如`StatusID(1)`的类型转换
+			if funIdent, ok := nodeType.Fun.(*ast.Ident); ok && len(nodeType.Args) == 1 {
+				if _, isBuiltin := builtinFuncs[funIdent.Name]; !isBuiltin {
+					typeName = funIdent.Name
+				}
+			}
+			return false
+		case *ast.Ident:
+			if refValueType, ok := constMap[nodeType.Name]; ok {
+				typeName = inferConstTypeName(refValueType, constMap, visited)
+			}
+		}
+		return true
+	})
+	return typeName
+}
@@ -0,0 +1,213 @@
+package aster
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+)
+
+// 对包内所有的常量求值，结果记录在ValueType.ConstValue中
+// 无法求值的常量（例如引用了其他包的常量）不会返回错误，只是ConstValue为nil
+func (this *PackageType) evalConsts() {
+	evaluator := this.newConstEvaluator()
+	for _, valueType := range this.Consts {
+		evaluator.evalValueType(valueType)
+	}
+
+	// 数组的长度可能引用了包内的常量
+	this.walkTypeTypes(func(typeType *TypeType) bool {
+		if typeType.Kind == Array && typeType.LenValue < 0 && typeType.astLen != nil {
+			typeType.evalLen(evaluator)
+		}
+		return true
+	})
+}
+
+func (this *PackageType) newConstEvaluator() *constEvaluator {
+	constMap := make(map[string]*ValueType, len(this.Consts))
+	for _, valueType := range this.Consts {
+		if valueType.Name != "_" {
+			constMap[valueType.Name] = valueType
+		}
+	}
+	return &constEvaluator{
+		pkgType:    this,
+		constMap:   constMap,
+		constKinds: make(map[*ValueType]constKind, len(constMap)),
+		evaluating: make(map[*ValueType]bool),
+		evaluated:  make(map[*ValueType]bool),
+	}
+}
+
+// 常量的类型，只区分影响求值结果的整数和浮点数（包括复数）
+type constKind int
+
+const (
+	untypedConst constKind = iota // 无类型常量，除法的行为由值本身决定，例如`3 / 2`是整数除法
+	intConst                      // 整数类型，例如`int`、`type StatusID int8`
+	floatConst                    // 浮点数或者复数类型
+	otherConst                    // 其他类型，以及无法确定底层类型的类型（例如`time.Duration`），按无类型常量处理
+)
+
+var basicConstKinds = map[string]constKind{
+	"int": intConst, "int8": intConst, "int16": intConst, "int32": intConst, "int64": intConst, "rune": intConst,
+	"uint": intConst, "uint8": intConst, "uint16": intConst, "uint32": intConst, "uint64": intConst, "byte": intConst, "uintptr": intConst,
+	"float32": floatConst, "float64": floatConst, "complex64": floatConst, "complex128": floatConst,
+}
+
+// 内置函数，调用它们不是类型转换
+var builtinFuncs = map[string]struct{}{
+	"append": {}, "cap": {}, "clear": {}, "close": {}, "complex": {}, "copy": {}, "delete": {}, "imag": {},
+	"len": {}, "make": {}, "max": {}, "min": {}, "new": {}, "panic": {}, "print": {}, "println": {}, "real": {}, "recover": {},
+}
+
+type constEvaluator struct {
+	pkgType  *PackageType // 用于解析常量声明的类型，可以为nil
+	constMap map[string]*ValueType
+	// 常量声明的类型，省略类型时为初始化表达式的类型
+	constKinds map[*ValueType]constKind
+	// 用于检测循环引用
+	evaluating map[*ValueType]bool
+	evaluated  map[*ValueType]bool
+}
+
+func (this *constEvaluator) evalValueType(valueType *ValueType) constant.Value {
+	if this.evaluated[valueType] || this.evaluating[valueType] {
+		return valueType.ConstValue
+	}
+	this.evaluating[valueType] = true
+	defer func() {
+		// go/constant在操作数类型不匹配时会panic（例如字符串与整数相加），视为无法求值
+		if recover() != nil {
+			valueType.ConstValue = nil
+		}
+		delete(this.evaluating, valueType)
+		this.evaluated[valueType] = true
+	}()
+	kind := this.getTypeKind(valueType.Type, make(map[*DefinedType]bool))
+	if valueType.astValue != nil {
+		if val, exprKind, err := this.evalTypedExpr(valueType.astValue, valueType.Iota); err == nil {
+			valueType.ConstValue = val
+			if valueType.Type == nil {
+				kind = exprKind
+			}
+		}
+	}
+	this.constKinds[valueType] = kind
+	return valueType.ConstValue
+}
+
+func (this *constEvaluator) evalExpr(astExpr ast.Expr, iota int) (constant.Value, error) {
+	val, _, err := this.evalTypedExpr(astExpr, iota)
+	return val, err
+}
+
+// 同时返回表达式的类型，用于区分`F / 2`（F是float64类型的常量）这样的浮点数除法
+func (this *constEvaluator) evalTypedExpr(astExpr ast.Expr, iota int) (constant.Value, constKind, error) {
+	switch exprType := astExpr.(type) {
+	case *ast.BasicLit:
+		val := constant.MakeFromLiteral(exprType.Value, exprType.Kind, 0)
+		if val.Kind() == constant.Unknown {
+			return nil, untypedConst, fmt.Errorf("constEvaluator.evalExpr()无法解析的字面量：%s", exprType.Value)
+		}
+		return val, untypedConst, nil
+	case *ast.Ident:
+		switch exprType.Name {
+		case "iota":
+			return constant.MakeInt64(int64(iota)), untypedConst, nil
+		case "true":
+			return constant.MakeBool(true), untypedConst, nil
+		case "false":
+			return constant.MakeBool(false), untypedConst, nil
+		}
+		if valueType, ok := this.constMap[exprType.Name]; ok {
+			if val := this.evalValueType(valueType); val != nil {
+				return val, this.constKinds[valueType], nil
+			}
+		}
+		return nil, untypedConst, fmt.Errorf("constEvaluator.evalExpr()无法求值的标识符：%s", exprType.Name)
+	case *ast.ParenExpr:
+		return this.evalTypedExpr(exprType.X, iota)
+	case *ast.UnaryExpr:
+		x, kind, err := this.evalTypedExpr(exprType.X, iota)
+		if err != nil {
+			return nil, kind, err
+		}
+		return constant.UnaryOp(exprType.Op, x, 0), kind, nil
+	case *ast.BinaryExpr:
+		x, xKind, err := this.evalTypedExpr(exprType.X, iota)
+		if err != nil {
+			return nil, xKind, err
+		}
+		y, yKind, err := this.evalTypedExpr(exprType.Y, iota)
+		if err != nil {
+			return nil, yKind, err
+		}
+		switch exprType.Op {
+		case token.SHL, token.SHR:
+			s, ok := constant.Uint64Val(constant.ToInt(y))
+			if !ok {
+				return nil, xKind, fmt.Errorf("constEvaluator.evalExpr()无效的位移量：%s", y)
+			}
+			return constant.Shift(x, exprType.Op, uint(s)), xKind, nil
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return constant.MakeBool(constant.Compare(x, exprType.Op, y)), untypedConst, nil
+		}
+		// 有类型的操作数决定结果的类型，无类型的操作数会被转换为该类型
+		kind := xKind
+		if kind == untypedConst {
+			kind = yKind
+		}
+		if exprType.Op == token.QUO {
+			if constant.Sign(y) == 0 {
+				return nil, kind, fmt.Errorf("constEvaluator.evalExpr()除数为0")
+			}
+			// 只有两个操作数都是整数类型，或者都是整数值的无类型常量时才是整数除法
+			if kind == intConst || (kind != floatConst && x.Kind() == constant.Int && y.Kind() == constant.Int) {
+				return constant.BinaryOp(constant.ToInt(x), token.QUO_ASSIGN, constant.ToInt(y)), kind, nil
+			}
+		}
+		return constant.BinaryOp(x, exprType.Op, y), kind, nil
+	case *ast.CallExpr:
+		// 仅支持形如`StatusID(1)`的类型转换
+		if len(exprType.Args) == 1 {
+			switch funExpr := exprType.Fun.(type) {
+			case *ast.Ident:
+				if _, ok := builtinFuncs[funExpr.Name]; !ok {
+					val, _, err := this.evalTypedExpr(exprType.Args[0], iota)
+					return val, this.getTypeKind(&TypeType{Kind: Ident, Name: funExpr.Name}, make(map[*DefinedType]bool)), err
+				}
+			case *ast.SelectorExpr, *ast.ParenExpr:
+				val, _, err := this.evalTypedExpr(exprType.Args[0], iota)
+				return val, otherConst, err
+			}
+		}
+		return nil, untypedConst, fmt.Errorf("constEvaluator.evalExpr()不支持的函数调用：%T", exprType.Fun)
+	default:
+		return nil, untypedConst, fmt.Errorf("constEvaluator.evalExpr()未处理的astExpr.(type)=%T", exprType)
+	}
+}
+
+// 根据底层类型判断常量的类型，typeType为nil时是无类型常量
+func (this *constEvaluator) getTypeKind(typeType *TypeType, visited map[*DefinedType]bool) constKind {
+	if typeType == nil {
+		return untypedConst
+	}
+	if this.pkgType != nil {
+		typeType = this.pkgType.ResolveAlias(typeType)
+	}
+	if typeType.Kind != Ident || typeType.PkgPath != "" {
+		return otherConst
+	}
+	if this.pkgType != nil {
+		if definedType, ok := this.pkgType.GetDefinedType(typeType.Name); ok && !visited[definedType] {
+			visited[definedType] = true
+			return this.getTypeKind(definedType.Type, visited)
+		}
+	}
+	if kind, ok := basicConstKinds[typeType.Name]; ok {
+		return kind
+	}
+	return otherConst
+}
@@ -3,6 +3,8 @@ package aster
 import (
 	"fmt"
 	"go/ast"
+	"go/token"
+	"go/types"
 	"strings"
 )
 
@@ -12,6 +14,14 @@ type FieldType struct {
 	Name string    `json:",omitempty"`
 	Docs []Comment `json:",omitempty"`
 	Type *TypeType `json:",omitempty"`
+	Pos  Position  `json:",omitempty"` // 有名字时从名字开始，匿名时从类型开始
+
+	// 只有设置了ParseOptions.TypeCheck时才有值，匿名的参数没有TypesObject
+	TypesType   types.Type   `json:"-"`
+	TypesObject types.Object `json:"-"`
+
+	astField *ast.Field
+	astName  *ast.Ident
 }
 
 // 因为语法上存在通过省略类型而实际有多个参数的情况，所以返回值是数组（例如`x,y string`）
@@ -31,7 +41,10 @@ func NewFieldTypes(astField *ast.Field) ([]*FieldType, error) {
 	// 	}()
 	// }
 	for i, astName := range astNames {
-		fieldType := &FieldType{}
+		fieldType := &FieldType{
+			astField: astField,
+			astName:  astName,
+		}
 		typeType, err := NewTypeType(astField.Type)
 		if err != nil {
 			return nil, err
@@ -52,6 +65,17 @@ func NewFieldTypes(astField *ast.Field) ([]*FieldType, error) {
 	return resFieldTypes, nil
 }
 
+func (this *FieldType) fillPosition(fSet *token.FileSet) {
+	if this.astField == nil {
+		return
+	}
+	pos := this.astField.Pos()
+	if this.astName != nil && this.astName.Pos().IsValid() {
+		pos = this.astName.Pos()
+	}
+	this.Pos = newPosition(fSet, pos, this.astField.End())
+}
+
 // 如果是匿名结构体，则直接返回原始名，形如`FiledType`
 // 如果是有字段名的类型，则返回`Field FiledType`
 func (this *FieldType) GetDecl() string {
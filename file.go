@@ -0,0 +1,184 @@
+package aster
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/token"
+	"strings"
+)
+
+// 用于描述包中的一个源文件
+type FileType struct {
+	// 当前文件所属的PackageType的引用
+	PackageType *PackageType `json:"-"`
+
+	Name    string        `json:",omitempty"` // 文件路径
+	IsTest  bool          `json:",omitempty"` // 是否是_test.go文件
+	Imports []*ImportType `json:",omitempty"` // 当前文件的import，不去重，包括`_`和`.`导入
+	// 文件的构建约束，形如`linux && amd64`，只有旧式的`// +build`时会转换为等价的表达式
+	BuildConstraint string `json:",omitempty"`
+	// package声明之前的注释，例如license，不包括构建约束以及包注释
+	Headers []Comment `json:",omitempty"`
+	// 包注释，即紧贴package声明的注释
+	Docs []Comment `json:",omitempty"`
+	Pos  Position  `json:",omitempty"`
+
+	// 以下是在当前文件中声明的内容，均是对PackageType中对应元素的引用
+	Structs    []*StructType    `json:"-"`
+	Interfaces []*InterfaceType `json:"-"`
+	Defineds   []*DefinedType   `json:"-"`
+	Funcs      []*FuncType      `json:"-"`
+	Methods    []*MethodType    `json:"-"`
+	Consts     []*ValueType     `json:"-"`
+	Vars       []*ValueType     `json:"-"`
+
+	astFile *ast.File
+	// 包名与import路径推测的包名不一致时，在linkImports中推断出的包名对应的import
+	inferredImports map[string]*ImportType
+}
+
+func (pkgType *PackageType) NewFileType(fileName string, astFile *ast.File) (*FileType, error) {
+	fileType := &FileType{
+		PackageType: pkgType,
+
+		Name:    fileName,
+		IsTest:  strings.HasSuffix(fileName, "_test.go"),
+		Imports: make([]*ImportType, 0, len(astFile.Imports)),
+		astFile: astFile,
+	}
+	for _, importSpec := range astFile.Imports {
+		fileType.Imports = append(fileType.Imports, NewImportType(importSpec))
+	}
+
+	fileType.Docs = newComments(astFile.Doc)
+	var plusBuildLines []string
+	for _, commentGroup := range astFile.Comments {
+		if commentGroup.Pos() >= astFile.Package {
+			break
+		}
+		if commentGroup == astFile.Doc {
+			continue
+		}
+		for _, comment := range commentGroup.List {
+			switch {
+			case constraint.IsGoBuild(comment.Text):
+				if expr, err := constraint.Parse(comment.Text); err == nil {
+					fileType.BuildConstraint = expr.String()
+				}
+			case constraint.IsPlusBuild(comment.Text):
+				plusBuildLines = append(plusBuildLines, comment.Text)
+			default:
+				fileType.Headers = append(fileType.Headers, comment.Text)
+			}
+		}
+	}
+	if fileType.BuildConstraint == "" && len(plusBuildLines) > 0 {
+		// 多行`// +build`之间是与的关系
+		exprs := make([]string, 0, len(plusBuildLines))
+		for _, line := range plusBuildLines {
+			if expr, err := constraint.Parse(line); err == nil {
+				exprs = append(exprs, "("+expr.String()+")")
+			}
+		}
+		if expr, err := constraint.Parse("//go:build " + strings.Join(exprs, " && ")); err == nil {
+			fileType.BuildConstraint = expr.String()
+		}
+	}
+	return fileType, nil
+}
+
+// 解析文件中的所有声明，结果会记录到所属的PackageType中，同时在当前文件中保留引用
+func (this *FileType) parseDecls() error {
+	pkgType := this.PackageType
+	structsLen, interfacesLen, definedsLen := len(pkgType.Structs), len(pkgType.Interfaces), len(pkgType.Defineds)
+	funcsLen, methodsLen, constsLen, varsLen := len(pkgType.Funcs), len(pkgType.Methods), len(pkgType.Consts), len(pkgType.Vars)
+
+	for _, decl := range this.astFile.Decls {
+		var err error
+		switch curDecl := decl.(type) {
+		case *ast.GenDecl:
+			err = pkgType.ParseGenDecl(curDecl)
+		case *ast.FuncDecl:
+			err = pkgType.ParseFuncDecl(curDecl)
+		case *ast.BadDecl:
+
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	this.Structs = append(this.Structs, pkgType.Structs[structsLen:]...)
+	this.Interfaces = append(this.Interfaces, pkgType.Interfaces[interfacesLen:]...)
+	this.Defineds = append(this.Defineds, pkgType.Defineds[definedsLen:]...)
+	this.Funcs = append(this.Funcs, pkgType.Funcs[funcsLen:]...)
+	this.Methods = append(this.Methods, pkgType.Methods[methodsLen:]...)
+	this.Consts = append(this.Consts, pkgType.Consts[constsLen:]...)
+	this.Vars = append(this.Vars, pkgType.Vars[varsLen:]...)
+	return nil
+}
+
+// 根据包名查找当前文件中的import，例如`eu`
+// 包名与import路径的最后一个元素不一致（例如路径`x/y`中声明的是`package z`）时，
+// 只能找到解析过程中根据类型引用推断出的import，参考PackageType.linkImports
+func (this *FileType) GetImport(pkgName string) (*ImportType, bool) {
+	for _, importType := range this.Imports {
+		if importType.GetPackageName() == pkgName {
+			return importType, true
+		}
+	}
+	importType, ok := this.inferredImports[pkgName]
+	return importType, ok
+}
+
+// 没有别名、且推测的包名没有在文件中作为限定符出现过的import，例如`"x/y"`中声明的是`package z`
+func (this *FileType) getUnmatchedImports() []*ImportType {
+	qualifiers := make(map[string]struct{}, len(this.Imports))
+	ast.Inspect(this.astFile, func(node ast.Node) bool {
+		if selectorExpr, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := selectorExpr.X.(*ast.Ident); ok {
+				qualifiers[ident.Name] = struct{}{}
+			}
+		}
+		return true
+	})
+	unmatched := make([]*ImportType, 0, len(this.Imports))
+	for _, importType := range this.Imports {
+		if importType.Alias != "" || importType.Name == "C" {
+			continue
+		}
+		if _, ok := qualifiers[importType.GetPackageName()]; !ok {
+			unmatched = append(unmatched, importType)
+		}
+	}
+	return unmatched
+}
+
+// 获取文件的AST
+func (this *FileType) GetASTFile() *ast.File {
+	return this.astFile
+}
+
+// 获取import部分的声明
+func (this *FileType) GetImportsDecl() string {
+	if len(this.Imports) == 0 {
+		return ""
+	}
+	sb := strings.Builder{}
+	sb.WriteString("import (\n")
+	for _, importType := range this.Imports {
+		sb.WriteString("\t" + importType.GetDecl() + "\n")
+	}
+	sb.WriteString(")\n")
+	return sb.String()
+}
+
+// 根据位置查找所在的文件，找不到时返回nil
+func (this *PackageType) getFileByPos(pos token.Pos) *FileType {
+	for _, fileType := range this.Files {
+		if fileType.astFile.Pos() <= pos && pos < fileType.astFile.End() {
+			return fileType
+		}
+	}
+	return nil
+}
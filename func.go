@@ -3,19 +3,28 @@ package aster
 import (
 	"fmt"
 	"go/ast"
+	"go/types"
 	"strings"
 )
 
 type FuncType struct {
-	Name    string       `json:",omitempty"`
-	Params  []*FieldType `json:",omitempty"`
-	Results []*FieldType `json:",omitempty"`
+	Name       string       `json:",omitempty"`
+	TypeParams []*FieldType `json:",omitempty"`
+	Params     []*FieldType `json:",omitempty"`
+	Results    []*FieldType `json:",omitempty"`
+	Pos        Position     `json:",omitempty"`
+
+	// 只有设置了ParseOptions.TypeCheck时才有值，TypesObject为*types.Func，TypesType为*types.Signature
+	TypesType   types.Type   `json:"-"`
+	TypesObject types.Object `json:"-"`
+
+	astNode ast.Node // *ast.FuncDecl或者*ast.Field
 
 	astBolckStmt *ast.BlockStmt
 }
 
 func NewFuncTypeByASTField(astField *ast.Field, astFuncType *ast.FuncType) (*FuncType, error) {
-	funcType := &FuncType{}
+	funcType := &FuncType{astNode: astField}
 
 	if len(astField.Names) > 0 {
 		funcType.Name = astField.Names[0].Name
@@ -42,12 +51,22 @@ func NewFuncTypeByASTField(astField *ast.Field, astFuncType *ast.FuncType) (*Fun
 }
 
 func NewFuncTypeByASTDecl(astDecl *ast.FuncDecl) (*FuncType, error) {
-	funcType := &FuncType{}
+	funcType := &FuncType{astNode: astDecl}
 	astFuncType := astDecl.Type
 
 	if astDecl.Name != nil {
 		funcType.Name = astDecl.Name.Name
 	}
+	if astFuncType.TypeParams != nil {
+		funcType.TypeParams = make([]*FieldType, 0, astFuncType.TypeParams.NumFields())
+		for _, astTypeParamField := range astFuncType.TypeParams.List {
+			fieldTypes, err := NewFieldTypes(astTypeParamField)
+			if err != nil {
+				return nil, err
+			}
+			funcType.TypeParams = append(funcType.TypeParams, fieldTypes...)
+		}
+	}
 	if astFuncType.Params != nil {
 		funcType.Params = make([]*FieldType, 0, astFuncType.Params.NumFields())
 		for _, astParamField := range astFuncType.Params.List {
@@ -92,11 +111,52 @@ func (this *FuncType) GetASTBlockSTMT() *ast.BlockStmt {
 	return this.astBolckStmt
 }
 
+// 不包含函数名的签名，形如`func(a string, u *User) error`
+func (this *FuncType) GetSignature() string {
+	sb := strings.Builder{}
+	sb.WriteString("func(")
+	for i, paramType := range this.Params {
+		sb.WriteString(paramType.GetDecl())
+		if i < len(this.Params)-1 {
+			sb.WriteString(", ")
+		}
+	}
+	sb.WriteString(")")
+
+	if len(this.Results) > 0 {
+		sb.WriteString(" ")
+	}
+	if len(this.Results) > 1 || (len(this.Results) == 1 && this.Results[0].Name != "") {
+		sb.WriteString("(")
+	}
+	for i, resultType := range this.Results {
+		sb.WriteString(resultType.GetDecl())
+		if i < len(this.Results)-1 {
+			sb.WriteString(", ")
+		}
+	}
+	if len(this.Results) > 1 || (len(this.Results) == 1 && this.Results[0].Name != "") {
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
 func (this *FuncType) String() string {
 
 	sb := strings.Builder{}
 
-	sb.WriteString("func " + this.Name + "(")
+	sb.WriteString("func " + this.Name)
+	if len(this.TypeParams) > 0 {
+		sb.WriteString("[")
+		for i, typeParam := range this.TypeParams {
+			sb.WriteString(typeParam.GetDecl())
+			if i < len(this.TypeParams)-1 {
+				sb.WriteString(", ")
+			}
+		}
+		sb.WriteString("]")
+	}
+	sb.WriteString("(")
 
 	for i, paramType := range this.Params {
 		sb.WriteString(paramType.GetDecl())
@@ -134,6 +194,7 @@ func NewInterfaceFuncType(astField *ast.Field, astFuncType *ast.FuncType) (*Inte
 		ASTField:    astField,
 		ASTFuncType: astFuncType,
 	}
+	funcType.astNode = astField
 
 	if len(astField.Names) > 0 {
 		funcType.Name = astField.Names[0].Name
@@ -1,6 +1,211 @@
 package aster
 
+import (
+	"go/ast"
+	"sort"
+	"strconv"
+	"strings"
+)
+
 type ImportType struct {
-	Name  string `json:",omitempty"`
-	Alias string `json:",omitempty"`
+	Name     string    `json:",omitempty"` // 完整的import路径
+	Alias    string    `json:",omitempty"` // 别名，可能是`_`或者`.`
+	Docs     []Comment `json:",omitempty"`
+	Comments []Comment `json:",omitempty"` // 行尾的注释
+	Pos      Position  `json:",omitempty"`
+
+	astSpec *ast.ImportSpec
+}
+
+func NewImportType(importSpec *ast.ImportSpec) *ImportType {
+	importType := &ImportType{
+		// delete the '"' prefix and sufix
+		Name:     strings.Trim(importSpec.Path.Value, `"`),
+		Docs:     newComments(importSpec.Doc),
+		Comments: newComments(importSpec.Comment),
+		astSpec:  importSpec,
+	}
+	if importSpec.Name != nil {
+		importType.Alias = importSpec.Name.Name
+	}
+	return importType
+}
+
+// 是否是形如`import _ "embed"`的匿名导入
+func (this *ImportType) IsBlank() bool {
+	return this.Alias == "_"
+}
+
+// 是否是形如`import . "fmt"`的导入
+func (this *ImportType) IsDot() bool {
+	return this.Alias == "."
+}
+
+// 获取完整的import声明，形如`eu "github.com/szyhf/go-aster/test/data/enum"`
+func (this *ImportType) GetDecl() string {
+	if this.Alias != "" {
+		return this.Alias + ` "` + this.Name + `"`
+	}
+	return `"` + this.Name + `"`
+}
+
+// 在代码中引用这个包时使用的名字，有别名时是别名，否则根据路径推测
+// 注意别名可能是`_`或者`.`
+func (this *ImportType) GetPackageName() string {
+	if this.Alias != "" {
+		return this.Alias
+	}
+	return guessPackageName(this.Name)
+}
+
+// 在没有加载对应包的情况下，根据import路径推测包名，规则与goimports一致：
+// 去掉末尾的主版本号（`/v2`、`.v3`），去掉`go-`前缀以及`-go`后缀，其余的非法字符替换为`_`
+// 例如`gopkg.in/yaml.v3`推测为`yaml`，`github.com/szyhf/go-aster`推测为`aster`
+func guessPackageName(importPath string) string {
+	lastSlash := strings.LastIndex(importPath, "/")
+	name := importPath[lastSlash+1:]
+	if isMajorVersion(name) && lastSlash > 0 {
+		// 形如`github.com/foo/bar/v2`
+		prevPath := importPath[:lastSlash]
+		name = prevPath[strings.LastIndex(prevPath, "/")+1:]
+	}
+	if i := strings.LastIndex(name, "."); i > 0 && isMajorVersion(name[i+1:]) {
+		// 形如`gopkg.in/yaml.v3`
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+	return strings.Map(func(r rune) rune {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') || r >= 0x80 {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// 形如`v2`
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// 预声明的类型，引用它们的Ident不可能来自`.`导入的包
+var predeclaredTypes = map[string]struct{}{
+	"any": {}, "bool": {}, "byte": {}, "comparable": {}, "complex64": {}, "complex128": {}, "error": {},
+	"float32": {}, "float64": {}, "int": {}, "int8": {}, "int16": {}, "int32": {}, "int64": {}, "rune": {},
+	"string": {}, "uint": {}, "uint8": {}, "uint16": {}, "uint32": {}, "uint64": {}, "uintptr": {},
+}
+
+// 把Selector类型关联到其所在文件中对应的import，并记录完整的import路径
+// 对于`.`导入的包，当文件中只有一个`.`导入时，包内没有声明的Ident类型会被认为来自这个包
+// 包名与import路径推测的包名不一致时，如果文件中只有一个无法匹配的限定符以及一个没有被使用的import，
+// 则认为两者对应，否则记录到Diagnostics中
+func (this *PackageType) linkImports() {
+	localTypes := this.getLocalTypeNames()
+	// 每个文件中无法匹配import的限定符，以及使用了该限定符的类型
+	unresolvedMap := make(map[*FileType]map[string][]*TypeType)
+
+	this.walkTypeTypes(func(typeType *TypeType) bool {
+		if typeType.astExpr == nil || (typeType.Kind != Selector && typeType.Kind != Ident) {
+			return true
+		}
+		curFile := this.getFileByPos(typeType.astExpr.Pos())
+		if curFile == nil {
+			return true
+		}
+
+		var matched *ImportType
+		if typeType.Kind == Selector {
+			matched, _ = curFile.GetImport(typeType.PkgName)
+			if matched == nil {
+				if unresolvedMap[curFile] == nil {
+					unresolvedMap[curFile] = make(map[string][]*TypeType)
+				}
+				unresolvedMap[curFile][typeType.PkgName] = append(unresolvedMap[curFile][typeType.PkgName], typeType)
+			}
+		} else if _, ok := localTypes[typeType.Name]; !ok {
+			for _, importType := range curFile.Imports {
+				if !importType.IsDot() {
+					continue
+				}
+				if matched != nil {
+					// 有多个`.`导入时无法确定来自哪个包
+					matched = nil
+					break
+				}
+				matched = importType
+			}
+		}
+		if matched != nil {
+			typeType.PkgPath = matched.Name
+			typeType.Import = matched
+		}
+		return true
+	})
+
+	for _, curFile := range this.Files {
+		unresolved := unresolvedMap[curFile]
+		if len(unresolved) == 0 {
+			continue
+		}
+		if unmatched := curFile.getUnmatchedImports(); len(unresolved) == 1 && len(unmatched) == 1 {
+			for pkgName, typeTypes := range unresolved {
+				curFile.inferredImports = map[string]*ImportType{pkgName: unmatched[0]}
+				for _, typeType := range typeTypes {
+					typeType.PkgPath = unmatched[0].Name
+					typeType.Import = unmatched[0]
+				}
+			}
+			continue
+		}
+		pkgNames := make([]string, 0, len(unresolved))
+		for pkgName := range unresolved {
+			pkgNames = append(pkgNames, pkgName)
+		}
+		sort.Strings(pkgNames)
+		for _, pkgName := range pkgNames {
+			this.addDiagnostic(unresolved[pkgName][0].Pos, "could not determine import of package qualifier %s", pkgName)
+		}
+	}
+}
+
+// 包内声明的类型名，包括预声明的类型以及所有的类型参数名
+func (this *PackageType) getLocalTypeNames() map[string]struct{} {
+	localTypes := make(map[string]struct{}, len(predeclaredTypes)+len(this.Structs)+len(this.Interfaces)+len(this.Defineds))
+	for name := range predeclaredTypes {
+		localTypes[name] = struct{}{}
+	}
+	for _, structType := range this.Structs {
+		localTypes[structType.Name] = struct{}{}
+		for _, typeParam := range structType.TypeParams {
+			localTypes[typeParam.Name] = struct{}{}
+		}
+	}
+	for _, interfaceType := range this.Interfaces {
+		localTypes[interfaceType.Name] = struct{}{}
+		for _, typeParam := range interfaceType.TypeParams {
+			localTypes[typeParam.Name] = struct{}{}
+		}
+	}
+	for _, definedType := range this.Defineds {
+		localTypes[definedType.Name] = struct{}{}
+		for _, typeParam := range definedType.TypeParams {
+			localTypes[typeParam.Name] = struct{}{}
+		}
+	}
+	for _, funcType := range this.Funcs {
+		for _, typeParam := range funcType.TypeParams {
+			localTypes[typeParam.Name] = struct{}{}
+		}
+	}
+	for _, methodType := range this.Methods {
+		// 方法的接受者中声明的类型参数，形如`func (l *List[T]) Push(v T)`中的`T`
+		for _, typeParam := range methodType.Receiver.Type.TypeParams {
+			localTypes[typeParam.Name] = struct{}{}
+		}
+	}
+	return localTypes
 }
@@ -0,0 +1,143 @@
+package aster
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 类型检查时用于导入依赖的包，不会调用go命令，也不会访问网络
+// 模块中的包（包括go.work中的模块以及replace到本地目录的模块）通过ModuleType找到目录，并使用parseFiles解析，
+// 因此同样会应用Overlay以及构建约束相关的选项；标准库中的包从GOROOT中解析
+type typesImporter struct {
+	opt    *ParseOptions // 解析依赖时使用的选项
+	fSet   *token.FileSet
+	module *ModuleType // 被检查的包不属于任何模块时为nil
+
+	// 以包所在的目录为键，标准库中vendor的包与模块中的同名包可能是不同的目录
+	pkgMap    map[string]*types.Package
+	importing map[string]bool
+}
+
+// dirPath为被检查的包所在的目录，用于查找go.mod和go.work
+func newTypesImporter(opt *ParseOptions, fSet *token.FileSet, dirPath string) *typesImporter {
+	// 依赖的包只需要声明
+	depOpt := *opt
+	depOpt.SkipComments = true
+	depOpt.SkipTests = true
+	depOpt.SkipFuncBodies = true
+	depOpt.TypeCheck = false
+	depOpt.FileSet = fSet
+	typesImporter := &typesImporter{
+		opt:       &depOpt,
+		fSet:      fSet,
+		pkgMap:    make(map[string]*types.Package, 32),
+		importing: make(map[string]bool),
+	}
+	if moduleType, err := LoadModule(dirPath, &depOpt); err == nil {
+		typesImporter.module = moduleType
+	}
+	return typesImporter
+}
+
+func (this *typesImporter) Import(path string) (*types.Package, error) {
+	return this.ImportFrom(path, "", 0)
+}
+
+func (this *typesImporter) ImportFrom(path, srcDir string, mode types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	pkgPath, dirPath, astFiles, err := this.load(path, srcDir)
+	if err != nil {
+		return nil, err
+	}
+	if typesPkg, ok := this.pkgMap[dirPath]; ok {
+		return typesPkg, nil
+	}
+	if this.importing[dirPath] {
+		return nil, fmt.Errorf("typesImporter.ImportFrom()包%s存在循环导入", path)
+	}
+	this.importing[dirPath] = true
+	defer delete(this.importing, dirPath)
+
+	buildCtx := this.opt.getBuildContext()
+	conf := &types.Config{
+		Importer:         this,
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Sizes:            types.SizesFor("gc", buildCtx.GOARCH),
+		// 依赖中的错误不影响被检查的包，忽略即可
+		Error: func(err error) {},
+	}
+	typesPkg, _ := conf.Check(pkgPath, this.fSet, astFiles, nil)
+	this.pkgMap[dirPath] = typesPkg
+	return typesPkg, nil
+}
+
+// 找到包的目录并解析，返回types.Package使用的路径
+func (this *typesImporter) load(path, srcDir string) (string, string, []*ast.File, error) {
+	if this.module != nil {
+		if dirPath, ok := this.module.GetPackageDir(path); ok && isDir(dirPath) {
+			if typesPkg, ok := this.pkgMap[dirPath]; ok {
+				return typesPkg.Path(), dirPath, nil, nil
+			}
+			pkgTyp, err := this.module.LoadPackage(path)
+			if err != nil {
+				return "", "", nil, err
+			}
+			astFiles := make([]*ast.File, 0, len(pkgTyp.Files))
+			for _, fileType := range pkgTyp.Files {
+				astFiles = append(astFiles, fileType.astFile)
+			}
+			return path, dirPath, astFiles, nil
+		}
+	}
+	return this.loadGoroot(path, srcDir)
+}
+
+// 标准库中的包，标准库自身引用的golang.org/x等包位于GOROOT/src/vendor中
+func (this *typesImporter) loadGoroot(path, srcDir string) (string, string, []*ast.File, error) {
+	buildCtx := this.opt.getBuildContext()
+	// 不处理cgo，与CGO_ENABLED=0时选择相同的文件
+	buildCtx.CgoEnabled = false
+	if buildCtx.GOROOT == "" {
+		return "", "", nil, fmt.Errorf("typesImporter.loadGoroot()无法找到包%s：GOROOT为空", path)
+	}
+	gorootSrc := filepath.Join(buildCtx.GOROOT, "src")
+	pkgPath, dirPath := path, filepath.Join(gorootSrc, filepath.FromSlash(path))
+	vendorDirPath := filepath.Join(gorootSrc, "vendor", filepath.FromSlash(path))
+	if srcDir != "" && strings.HasPrefix(srcDir, gorootSrc+string(filepath.Separator)) && isDir(vendorDirPath) {
+		pkgPath, dirPath = "vendor/"+path, vendorDirPath
+	}
+	if !isDir(dirPath) {
+		return "", "", nil, fmt.Errorf("typesImporter.loadGoroot()无法找到包%s", path)
+	}
+	if typesPkg, ok := this.pkgMap[dirPath]; ok {
+		return typesPkg.Path(), dirPath, nil, nil
+	}
+	// 以目录导入时go/build只会读取目录中的文件，不会调用go命令
+	buildPkg, err := buildCtx.ImportDir(dirPath, 0)
+	if err != nil {
+		return "", "", nil, err
+	}
+	astFiles := make([]*ast.File, 0, len(buildPkg.GoFiles))
+	for _, fileName := range buildPkg.GoFiles {
+		astFile, err := parser.ParseFile(this.fSet, filepath.Join(dirPath, fileName), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", "", nil, err
+		}
+		astFiles = append(astFiles, astFile)
+	}
+	return pkgPath, dirPath, astFiles, nil
+}
+
+func isDir(dirPath string) bool {
+	fileInfo, err := os.Stat(dirPath)
+	return err == nil && fileInfo.IsDir()
+}
@@ -9,16 +9,33 @@ import (
 type InterfaceType struct {
 	PackageType *PackageType
 
-	Name  string `json:",omitempty"`
-	Funcs []*InterfaceFuncType
-	Docs  []Comment
+	Name       string       `json:",omitempty"`
+	TypeParams []*FieldType `json:",omitempty"` // 泛型接口的类型参数，形如`Getter[T any]`中的`T any`
+	Funcs      []*InterfaceFuncType
+	Embeds     []*TypeType `json:",omitempty"` // 嵌入的接口或者类型约束，形如`io.Reader`、`~int | ~string`
+	Docs       []Comment
+	Pos        Position `json:",omitempty"`
+
+	astSpec *ast.TypeSpec
 }
 
 func (pkgType *PackageType) NewInterfaceType(astGenDecl *ast.GenDecl, typeSpec *ast.TypeSpec, astInterface *ast.InterfaceType) (*InterfaceType, error) {
 	interfaceType := &InterfaceType{
 		PackageType: pkgType,
 
-		Name: typeSpec.Name.String(),
+		Name:    typeSpec.Name.String(),
+		astSpec: typeSpec,
+	}
+
+	if typeSpec.TypeParams != nil {
+		interfaceType.TypeParams = make([]*FieldType, 0, typeSpec.TypeParams.NumFields())
+		for _, astField := range typeSpec.TypeParams.List {
+			fieldTypes, err := NewFieldTypes(astField)
+			if err != nil {
+				return nil, err
+			}
+			interfaceType.TypeParams = append(interfaceType.TypeParams, fieldTypes...)
+		}
 	}
 
 	if astInterface.Methods != nil {
@@ -30,16 +47,14 @@ func (pkgType *PackageType) NewInterfaceType(astGenDecl *ast.GenDecl, typeSpec *
 				if err != nil {
 					return nil, err
 				}
-			case *ast.Ident:
-				// 嵌入自己包的接口
-				// 在interface中嵌入的子interface
-				// TODO: 会导致无法拿到Interface的所有方法，需要额外处理。
-				// fmt.Println("NewInterfaceType(): 在interface中嵌入的子interface，会导致无法拿到Interface的所有方法，需要额外处理。")
-			case *ast.SelectorExpr:
-				// 嵌入其他包的接口
-				// 在interface中嵌入的子interface
-				// TODO: 会导致无法拿到Interface的所有方法，需要额外处理。
-				// fmt.Println("NewInterfaceType(): 在interface中嵌入的子interface，会导致无法拿到Interface的所有方法，需要额外处理。")
+			case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr, *ast.UnaryExpr, *ast.BinaryExpr, *ast.ParenExpr:
+				// 嵌入自己包或者其他包的接口，以及泛型的类型约束
+				// 嵌入的接口的方法不会合并到Funcs中，完整的方法集可以通过AsType()获取
+				embedType, err := NewTypeType(astExpr)
+				if err != nil {
+					return nil, err
+				}
+				interfaceType.Embeds = append(interfaceType.Embeds, embedType)
 			default:
 				return nil, fmt.Errorf("NewInterfaceType()未处理的MethodField: %T", astExpr)
 			}
@@ -65,6 +80,32 @@ func (this *InterfaceType) ParseInterfaceFuncType(astField *ast.Field, astFuncTy
 	return err
 }
 
+// 声明时的名字，形如 Getter[T any]
+func (this *InterfaceType) GetDeclName() string {
+	if len(this.TypeParams) == 0 {
+		return this.Name
+	}
+	sb := &strings.Builder{}
+	sb.WriteString(this.Name + "[")
+	for i, typeParam := range this.TypeParams {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(typeParam.GetDecl())
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// 直接声明的方法的签名，不包括嵌入的接口中的方法
+func (this *InterfaceType) getFuncTypes() []*FuncType {
+	funcTypes := make([]*FuncType, 0, len(this.Funcs))
+	for _, funcType := range this.Funcs {
+		funcTypes = append(funcTypes, &funcType.FuncType)
+	}
+	return funcTypes
+}
+
 func (this *InterfaceType) String() string {
 	sb := strings.Builder{}
 
@@ -72,7 +113,10 @@ func (this *InterfaceType) String() string {
 		sb.WriteString(doc)
 	}
 
-	sb.WriteString("type " + this.Name + " interface {\n")
+	sb.WriteString("type " + this.GetDeclName() + " interface {\n")
+	for _, embedType := range this.Embeds {
+		sb.WriteString("\t" + embedType.GetDecl() + "\n")
+	}
 	for _, fun := range this.Funcs {
 		sb.WriteString("\t" + fun.Name + "(")
 		for i, paramType := range fun.Params {
@@ -35,6 +35,15 @@ func NewMethodType(astFuncDecl *ast.FuncDecl) (*MethodType, error) {
 	return methodType, nil
 }
 
+// 接受者的类型名，不包含指针以及类型参数，形如`Struct`
+func (this *MethodType) GetRecvTypeName() string {
+	recvType := this.Receiver.Type
+	if recvType.Kind == Star {
+		recvType = recvType.Elem
+	}
+	return recvType.Name
+}
+
 func (this *MethodType) String() string {
 	sb := strings.Builder{}
 
@@ -0,0 +1,328 @@
+package aster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// 用于描述go.mod文件的内容
+type ModFileType struct {
+	Name      string         `json:",omitempty"` // go.mod的路径
+	Dir       string         `json:",omitempty"` // go.mod所在目录的绝对路径
+	Path      string         `json:",omitempty"` // 模块路径，形如`github.com/szyhf/go-aster`
+	GoVersion string         `json:",omitempty"` // 形如`1.18`
+	Requires  []*RequireType `json:",omitempty"`
+	Replaces  []*ReplaceType `json:",omitempty"`
+}
+
+// 用于描述go.work文件的内容
+type WorkFileType struct {
+	Name      string         `json:",omitempty"` // go.work的路径
+	Dir       string         `json:",omitempty"` // go.work所在目录的绝对路径
+	GoVersion string         `json:",omitempty"`
+	Uses      []string       `json:",omitempty"` // use指令中的目录，已转换为绝对路径
+	Replaces  []*ReplaceType `json:",omitempty"`
+}
+
+// 形如`require github.com/szyhf/go-aster v1.0.0 // indirect`
+type RequireType struct {
+	Path     string `json:",omitempty"`
+	Version  string `json:",omitempty"`
+	Indirect bool   `json:",omitempty"`
+}
+
+// 形如`replace github.com/szyhf/go-aster v1.0.0 => ../go-aster`
+type ReplaceType struct {
+	Path       string `json:",omitempty"`
+	Version    string `json:",omitempty"` // 为空时替换所有版本
+	NewPath    string `json:",omitempty"`
+	NewVersion string `json:",omitempty"` // 为空时NewPath是本地目录
+	// 替换为本地目录时，为目录的绝对路径（相对路径相对于声明replace的文件所在的目录）
+	Dir string `json:",omitempty"`
+}
+
+// 是否替换为本地目录
+func (this *ReplaceType) IsLocal() bool {
+	return this.Dir != ""
+}
+
+// 目录在当前模块中的导入路径，目录不在当前模块中时返回false
+func (this *ModFileType) GetImportPath(absDirPath string) (string, bool) {
+	if this.Path == "" {
+		return "", false
+	}
+	return joinImportPath(this.Path, this.Dir, absDirPath)
+}
+
+// 从absDirPath开始向上查找最近的go.mod，找不到时返回nil
+func findModFile(absDirPath string) (*ModFileType, error) {
+	filePath, err := findUpward(absDirPath, "go.mod")
+	if err != nil || filePath == "" {
+		return nil, err
+	}
+	return ParseModFile(filePath)
+}
+
+// 从absDirPath开始向上查找最近的go.work，找不到时返回nil
+func findWorkFile(absDirPath string) (*WorkFileType, error) {
+	filePath, err := findUpward(absDirPath, "go.work")
+	if err != nil || filePath == "" {
+		return nil, err
+	}
+	return ParseWorkFile(filePath)
+}
+
+func findUpward(absDirPath, fileName string) (string, error) {
+	for dirPath := absDirPath; ; {
+		filePath := filepath.Join(dirPath, fileName)
+		fileInfo, err := os.Stat(filePath)
+		if err == nil && !fileInfo.IsDir() {
+			return filePath, nil
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		parentDir := filepath.Dir(dirPath)
+		if parentDir == dirPath {
+			return "", nil
+		}
+		dirPath = parentDir
+	}
+}
+
+// 以模块路径和模块目录推断absDirPath的导入路径，absDirPath不在模块目录中时返回false
+func joinImportPath(modPath, modDir, absDirPath string) (string, bool) {
+	relPath, err := filepath.Rel(modDir, absDirPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if relPath == "." {
+		return modPath, true
+	}
+	return modPath + "/" + filepath.ToSlash(relPath), true
+}
+
+func ParseModFile(filePath string) (*ModFileType, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseModData(filePath, data)
+}
+
+// filePath用于计算目录以及报告错误，不会读取
+func ParseModData(filePath string, data []byte) (*ModFileType, error) {
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+	modFile := &ModFileType{
+		Name: filePath,
+		Dir:  filepath.Dir(absFilePath),
+	}
+	err = parseDirectives(filePath, data, func(verb string, args []string, comment string) error {
+		switch verb {
+		case "module":
+			if len(args) != 1 {
+				return fmt.Errorf("无效的module指令")
+			}
+			modFile.Path = args[0]
+		case "go":
+			if len(args) != 1 {
+				return fmt.Errorf("无效的go指令")
+			}
+			modFile.GoVersion = args[0]
+		case "require":
+			if len(args) != 2 {
+				return fmt.Errorf("无效的require指令")
+			}
+			modFile.Requires = append(modFile.Requires, &RequireType{
+				Path:     args[0],
+				Version:  args[1],
+				Indirect: comment == "indirect" || strings.HasPrefix(comment, "indirect;"),
+			})
+		case "replace":
+			replaceType, err := newReplaceType(modFile.Dir, args)
+			if err != nil {
+				return err
+			}
+			modFile.Replaces = append(modFile.Replaces, replaceType)
+		}
+		// exclude、retract、toolchain、godebug等不影响导入路径的指令直接忽略
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return modFile, nil
+}
+
+func ParseWorkFile(filePath string) (*WorkFileType, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWorkData(filePath, data)
+}
+
+// filePath用于计算目录以及报告错误，不会读取
+func ParseWorkData(filePath string, data []byte) (*WorkFileType, error) {
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+	workFile := &WorkFileType{
+		Name: filePath,
+		Dir:  filepath.Dir(absFilePath),
+	}
+	err = parseDirectives(filePath, data, func(verb string, args []string, comment string) error {
+		switch verb {
+		case "go":
+			if len(args) != 1 {
+				return fmt.Errorf("无效的go指令")
+			}
+			workFile.GoVersion = args[0]
+		case "use":
+			if len(args) != 1 {
+				return fmt.Errorf("无效的use指令")
+			}
+			workFile.Uses = append(workFile.Uses, toAbsPath(workFile.Dir, args[0]))
+		case "replace":
+			replaceType, err := newReplaceType(workFile.Dir, args)
+			if err != nil {
+				return err
+			}
+			workFile.Replaces = append(workFile.Replaces, replaceType)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return workFile, nil
+}
+
+// args形如`[old => new]`、`[old v1 => new]`、`[old => new v2]`或者`[old v1 => new v2]`
+func newReplaceType(baseDir string, args []string) (*ReplaceType, error) {
+	arrowIdx := -1
+	for i, arg := range args {
+		if arg == "=>" {
+			arrowIdx = i
+			break
+		}
+	}
+	oldArgs, newArgs := args, []string(nil)
+	if arrowIdx >= 0 {
+		oldArgs, newArgs = args[:arrowIdx], args[arrowIdx+1:]
+	}
+	if arrowIdx < 0 || len(oldArgs) < 1 || len(oldArgs) > 2 || len(newArgs) < 1 || len(newArgs) > 2 {
+		return nil, fmt.Errorf("无效的replace指令")
+	}
+	replaceType := &ReplaceType{
+		Path:    oldArgs[0],
+		NewPath: newArgs[0],
+	}
+	if len(oldArgs) == 2 {
+		replaceType.Version = oldArgs[1]
+	}
+	if len(newArgs) == 2 {
+		replaceType.NewVersion = newArgs[1]
+	} else if isLocalModPath(replaceType.NewPath) {
+		replaceType.Dir = toAbsPath(baseDir, replaceType.NewPath)
+	}
+	return replaceType, nil
+}
+
+// 与go命令的规则一致，以`./`、`../`开头或者是绝对路径时才是本地目录
+func isLocalModPath(modPath string) bool {
+	return modPath == "." || modPath == ".." ||
+		strings.HasPrefix(modPath, "./") || strings.HasPrefix(modPath, "../") ||
+		strings.HasPrefix(modPath, `.\`) || strings.HasPrefix(modPath, `..\`) ||
+		filepath.IsAbs(modPath)
+}
+
+func toAbsPath(baseDir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(baseDir, filepath.FromSlash(path))
+}
+
+// 逐行解析go.mod和go.work共用的语法，包括`require (...)`这样的分组
+// fn的comment为行尾注释去掉`//`之后的内容，例如`indirect`
+func parseDirectives(filePath string, data []byte, fn func(verb string, args []string, comment string) error) error {
+	blockVerb := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		tokens, comment, err := splitModLine(line)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %v", filePath, i+1, err)
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		var verb string
+		var args []string
+		switch {
+		case blockVerb != "" && len(tokens) == 1 && tokens[0] == ")":
+			blockVerb = ""
+			continue
+		case blockVerb != "":
+			verb, args = blockVerb, tokens
+		case len(tokens) == 2 && tokens[1] == "(":
+			blockVerb = tokens[0]
+			continue
+		default:
+			verb, args = tokens[0], tokens[1:]
+		}
+		if err := fn(verb, args, comment); err != nil {
+			return fmt.Errorf("%s:%d: %v", filePath, i+1, err)
+		}
+	}
+	if blockVerb != "" {
+		return fmt.Errorf("%s: %s分组没有结束", filePath, blockVerb)
+	}
+	return nil
+}
+
+// 拆分一行，支持双引号和反引号包裹的字符串
+func splitModLine(line string) ([]string, string, error) {
+	tokens := make([]string, 0, 4)
+	for i := 0; i < len(line); {
+		switch c := line[i]; {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(line[i:], "//"):
+			return tokens, strings.TrimSpace(line[i+2:]), nil
+		case c == '"' || c == '`':
+			end := i + 1
+			for end < len(line) && line[end] != c {
+				if c == '"' && line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return nil, "", fmt.Errorf("字符串没有结束")
+			}
+			token, err := strconv.Unquote(line[i : end+1])
+			if err != nil {
+				return nil, "", err
+			}
+			tokens = append(tokens, token)
+			i = end + 1
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			start := i
+			for i < len(line) && !strings.ContainsRune(" \t\r()\"`", rune(line[i])) && !strings.HasPrefix(line[i:], "//") {
+				i++
+			}
+			tokens = append(tokens, line[start:i])
+		}
+	}
+	return tokens, "", nil
+}
@@ -0,0 +1,170 @@
+package aster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// 用于描述一个主模块（有go.work时为工作区中的所有模块），可以根据导入路径找到并解析包
+// 只根据go.mod和go.work推断目录，不会调用go命令，也不会访问网络
+// 能够解析的包仅限于主模块以及replace到本地目录的模块中的包
+type ModuleType struct {
+	Dir     string         `json:",omitempty"` // 调用LoadModule时传入的目录的绝对路径
+	Main    *ModFileType   `json:",omitempty"` // Dir所属的模块
+	Work    *WorkFileType  `json:",omitempty"` // 没有go.work或者GOWORK=off时为nil
+	Modules []*ModFileType `json:",omitempty"` // 所有主模块，有go.work时为use中的所有模块
+	Options *ParseOptions  `json:"-"`          // 解析包时使用的选项
+
+	// 已经解析过的包，以导入路径为键
+	pkgMap map[string]*PackageType
+}
+
+// 与go命令一样，先查找go.work（可以通过环境变量GOWORK指定路径或者设置为off），再查找dirPath所属的go.mod
+// opts用于之后解析包，可以省略
+func LoadModule(dirPath string, opts ...*ParseOptions) (*ModuleType, error) {
+	absDirPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	moduleType := &ModuleType{
+		Dir:     absDirPath,
+		Options: getParseOptions(opts),
+		pkgMap:  make(map[string]*PackageType, 16),
+	}
+
+	moduleType.Main, err = findModFile(absDirPath)
+	if err != nil {
+		return nil, err
+	}
+	if moduleType.Main == nil {
+		return nil, fmt.Errorf("LoadModule()目录%s不属于任何模块", dirPath)
+	}
+
+	switch goWork := os.Getenv("GOWORK"); goWork {
+	case "off":
+	case "":
+		moduleType.Work, err = findWorkFile(absDirPath)
+	default:
+		moduleType.Work, err = ParseWorkFile(goWork)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if moduleType.Work == nil {
+		moduleType.Modules = []*ModFileType{moduleType.Main}
+		return moduleType, nil
+	}
+	for _, useDir := range moduleType.Work.Uses {
+		if useDir == moduleType.Main.Dir {
+			moduleType.Modules = append(moduleType.Modules, moduleType.Main)
+			continue
+		}
+		modFile, err := ParseModFile(filepath.Join(useDir, "go.mod"))
+		if err != nil {
+			return nil, err
+		}
+		moduleType.Modules = append(moduleType.Modules, modFile)
+	}
+	return moduleType, nil
+}
+
+// 根据导入路径找到包所在的目录，不检查目录是否存在
+// 在所有主模块以及replace到本地目录的模块中查找，有多个模块匹配时使用模块路径最长的
+func (this *ModuleType) GetPackageDir(importPath string) (string, bool) {
+	modPath, modDir := "", ""
+	match := func(path, dir string) {
+		if len(path) <= len(modPath) {
+			return
+		}
+		if importPath == path || strings.HasPrefix(importPath, path+"/") {
+			modPath, modDir = path, dir
+		}
+	}
+	for _, modFile := range this.Modules {
+		match(modFile.Path, modFile.Dir)
+	}
+	for _, replaceType := range this.getLocalReplaces() {
+		match(replaceType.Path, replaceType.Dir)
+	}
+	if modDir == "" {
+		return "", false
+	}
+	relPath := strings.TrimPrefix(importPath[len(modPath):], "/")
+	return filepath.Join(modDir, filepath.FromSlash(relPath)), true
+}
+
+// 所有生效的本地replace，go.work中的replace优先于各个go.mod中的
+func (this *ModuleType) getLocalReplaces() []*ReplaceType {
+	replaces := make([]*ReplaceType, 0, 8)
+	if this.Work != nil {
+		replaces = append(replaces, this.Work.Replaces...)
+	}
+	for _, modFile := range this.Modules {
+		replaces = append(replaces, modFile.Replaces...)
+	}
+	localReplaces := make([]*ReplaceType, 0, len(replaces))
+	replacedSet := make(map[string]struct{}, len(replaces))
+	for _, replaceType := range replaces {
+		if _, ok := replacedSet[replaceType.Path]; ok {
+			continue
+		}
+		replacedSet[replaceType.Path] = struct{}{}
+		if replaceType.IsLocal() {
+			localReplaces = append(localReplaces, replaceType)
+		}
+	}
+	return localReplaces
+}
+
+// 根据导入路径解析包，解析结果会被缓存，目录中的外部测试包通过PackageType.XTest获取
+func (this *ModuleType) LoadPackage(importPath string) (*PackageType, error) {
+	if pkgTyp, ok := this.pkgMap[importPath]; ok {
+		return pkgTyp, nil
+	}
+	dirPath, ok := this.GetPackageDir(importPath)
+	if !ok {
+		return nil, fmt.Errorf("ModuleType.LoadPackage()无法找到包%s所在的目录", importPath)
+	}
+	pkgsTyp, err := ParseDir(dirPath, nil, this.Options)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkgTyp := range pkgsTyp {
+		if pkgTyp.TestFor != nil {
+			continue
+		}
+		// replace的目标目录中的go.mod可能声明了不同的模块路径，以实际使用的导入路径为准
+		pkgTyp.ImportPath = importPath
+		if pkgTyp.XTest != nil {
+			pkgTyp.XTest.ImportPath = importPath + "_test"
+		}
+		this.pkgMap[importPath] = pkgTyp
+		return pkgTyp, nil
+	}
+	return nil, fmt.Errorf("ModuleType.LoadPackage()目录%s中没有包%s", dirPath, importPath)
+}
+
+// 解析所有主模块中的包，返回的包按导入路径排序
+func (this *ModuleType) LoadPackages() ([]*PackageType, error) {
+	pkgsTyp := make([]*PackageType, 0, 16)
+	for _, modFile := range this.Modules {
+		treePkgsTyp, err := ParseTree(modFile.Dir, this.Options)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkgTyp := range treePkgsTyp {
+			if pkgTyp.TestFor == nil {
+				this.pkgMap[pkgTyp.ImportPath] = pkgTyp
+			}
+		}
+		pkgsTyp = append(pkgsTyp, treePkgsTyp...)
+	}
+	sort.SliceStable(pkgsTyp, func(i, j int) bool {
+		return pkgsTyp[i].ImportPath < pkgsTyp[j].ImportPath
+	})
+	return pkgsTyp, nil
+}
@@ -0,0 +1,180 @@
+package aster
+
+import (
+	"bytes"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 解析时的选项，零值即为默认行为：保留注释、包含_test.go文件、不评估构建约束、保留函数体
+type ParseOptions struct {
+	// 额外的解析模式，例如parser.AllErrors，会与注释相关的模式合并
+	Mode parser.Mode
+	// 是否丢弃注释，丢弃后Docs、Comments等都为空
+	SkipComments bool
+	// 是否跳过_test.go文件
+	SkipTests bool
+
+	// 用于评估构建约束（`//go:build`以及`_linux.go`这样的文件名后缀），规则与go/build一致
+	// BuildContext、BuildTags、GOOS、GOARCH都为空时不评估构建约束，所有文件都会被解析
+	// BuildContext为空时以go/build.Default为基础，BuildTags、GOOS、GOARCH不为空时覆盖其中对应的值
+	// 不满足构建约束的文件不会被解析，其路径记录在PackageType.IgnoredFiles中
+	BuildContext *build.Context
+	BuildTags    []string
+	GOOS         string
+	GOARCH       string
+
+	// 是否丢弃函数体，只关心声明时可以减少内存占用
+	SkipFuncBodies bool
+	// 是否使用go/types进行类型检查，检查结果记录在TypeType、FieldType、FuncType的TypesType和TypesObject中
+	// 依赖的包根据go.mod、go.work找到目录后从源码解析（同样应用Overlay以及构建约束相关的选项），标准库从GOROOT中解析，
+	// 不会调用go命令，也不会访问网络；无法找到的包以及类型错误记录在PackageType.Diagnostics中
+	// 同时设置了SkipFuncBodies时，只在函数体中使用的import会被报告为未使用
+	TypeCheck bool
+	// 解析时使用的FileSet，为空时会新建一个
+	FileSet *token.FileSet
+	// 以文件路径为键的文件内容，解析时优先于磁盘上的文件，可用于解析编辑器中尚未保存的内容
+	// 路径可以是相对路径，比较时会转换为绝对路径；ParseDir也会解析只存在于Overlay中的文件
+	Overlay map[string][]byte
+}
+
+// 取第一个非空的选项，都为空时返回默认选项
+func getParseOptions(opts []*ParseOptions) *ParseOptions {
+	for _, opt := range opts {
+		if opt != nil {
+			return opt
+		}
+	}
+	return &ParseOptions{}
+}
+
+func (this *ParseOptions) getFileSet() *token.FileSet {
+	if this.FileSet != nil {
+		return this.FileSet
+	}
+	return token.NewFileSet()
+}
+
+func (this *ParseOptions) getMode() parser.Mode {
+	if this.SkipComments {
+		return this.Mode &^ parser.ParseComments
+	}
+	return this.Mode | parser.ParseComments
+}
+
+// 是否需要评估构建约束
+func (this *ParseOptions) hasBuildConstraints() bool {
+	return this.BuildContext != nil || len(this.BuildTags) > 0 || this.GOOS != "" || this.GOARCH != ""
+}
+
+// 获取Overlay中的文件内容
+func (this *ParseOptions) getOverlay(filePath string) ([]byte, bool) {
+	if len(this.Overlay) == 0 {
+		return nil, false
+	}
+	if src, ok := this.Overlay[filePath]; ok {
+		return src, true
+	}
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, false
+	}
+	for overlayPath, src := range this.Overlay {
+		if overlayAbsPath, err := filepath.Abs(overlayPath); err == nil && overlayAbsPath == absPath {
+			return src, true
+		}
+	}
+	return nil, false
+}
+
+// Overlay中位于dirPath下的文件名，已排序
+func (this *ParseOptions) getOverlayFileNames(dirPath string) []string {
+	if len(this.Overlay) == 0 {
+		return nil
+	}
+	absDirPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return nil
+	}
+	fileNames := make([]string, 0, len(this.Overlay))
+	for overlayPath := range this.Overlay {
+		overlayAbsPath, err := filepath.Abs(overlayPath)
+		if err == nil && filepath.Dir(overlayAbsPath) == absDirPath {
+			fileNames = append(fileNames, filepath.Base(overlayAbsPath))
+		}
+	}
+	sort.Strings(fileNames)
+	return fileNames
+}
+
+func (this *ParseOptions) getBuildContext() build.Context {
+	buildCtx := build.Default
+	if this.BuildContext != nil {
+		buildCtx = *this.BuildContext
+	}
+	buildCtx.OpenFile = func(filePath string) (io.ReadCloser, error) {
+		if src, ok := this.getOverlay(filePath); ok {
+			return io.NopCloser(bytes.NewReader(src)), nil
+		}
+		return os.Open(filePath)
+	}
+	if len(this.BuildTags) > 0 {
+		buildCtx.BuildTags = this.BuildTags
+	}
+	if this.GOOS != "" {
+		buildCtx.GOOS = this.GOOS
+	}
+	if this.GOARCH != "" {
+		buildCtx.GOARCH = this.GOARCH
+	}
+	return buildCtx
+}
+
+// 是否需要跳过测试文件
+func (this *ParseOptions) skipTestFile(fileName string) bool {
+	return this.SkipTests && strings.HasSuffix(fileName, "_test.go")
+}
+
+// 判断文件是否满足构建约束，没有设置构建约束时总是返回true
+// 读取文件失败时也返回true，交给parser报告错误
+func (this *ParseOptions) matchBuildConstraint(dirPath, fileName string) bool {
+	if !this.hasBuildConstraints() {
+		return true
+	}
+	buildCtx := this.getBuildContext()
+	match, err := buildCtx.MatchFile(dirPath, fileName)
+	return match || err != nil
+}
+
+// 按选项处理解析后的文件
+func (this *ParseOptions) trimFile(astFile *ast.File) {
+	if !this.SkipFuncBodies {
+		return
+	}
+	for _, astDecl := range astFile.Decls {
+		if funcDecl, ok := astDecl.(*ast.FuncDecl); ok {
+			funcDecl.Body = nil
+		}
+	}
+}
+
+// 用于只存在于Overlay中的文件
+type overlayFileInfo struct {
+	name string
+	size int64
+}
+
+func (this *overlayFileInfo) Name() string       { return this.name }
+func (this *overlayFileInfo) Size() int64        { return this.size }
+func (this *overlayFileInfo) Mode() os.FileMode  { return 0444 }
+func (this *overlayFileInfo) ModTime() time.Time { return time.Time{} }
+func (this *overlayFileInfo) IsDir() bool        { return false }
+func (this *overlayFileInfo) Sys() interface{}   { return nil }
@@ -3,23 +3,63 @@ package aster
 import (
 	"fmt"
 	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type PackageType struct {
+	// 解析时使用的FileSet，用于把token.Pos转换为具体的位置
+	FileSet *token.FileSet `json:"-"`
+
 	Name       string           `json:",omitempty"`
-	Imports    []*ImportType    `json:",omitempty"`
+	Dir        string           `json:",omitempty"` // 包所在目录的绝对路径
+	ImportPath string           `json:",omitempty"` // 根据最近的go.mod推断的导入路径，找不到go.mod时为空
+	Files      []*FileType      `json:",omitempty"`
+	Imports    []*ImportType    `json:",omitempty"` // 所有文件的import的并集，以别名和路径去重
 	Interfaces []*InterfaceType `json:",omitempty"`
 	Structs    []*StructType    `json:",omitempty"`
 	Funcs      []*FuncType      `json:",omitempty"` // 不考虑Method
 	Methods    []*MethodType    `json:",omitempty"`
+	Consts     []*ValueType     `json:",omitempty"`
+	Vars       []*ValueType     `json:",omitempty"`
+	Enums      []*EnumType      `json:",omitempty"`
+	Defineds   []*DefinedType   `json:",omitempty"` // 非struct、非interface的具名类型
+
+	// 因为不满足构建约束而没有解析的文件路径，只有设置了构建约束相关的选项时才会记录
+	// 目录中所有的文件都被排除时，ParseDir返回的包只有Name、Dir、ImportPath以及IgnoredFiles
+	IgnoredFiles []string `json:",omitempty"`
+
+	// 解析过程中发现的、不影响解析结果的问题，例如接受者是其他包的类型的方法
+	Diagnostics []*Diagnostic `json:",omitempty"`
+
+	// 只有设置了ParseOptions.TypeCheck时才有值
+	TypesPackage *types.Package `json:"-"`
+	TypesInfo    *types.Info    `json:"-"`
+
+	// 同一目录下的外部测试包（package xxx_test），只有通过ParseDir等按目录解析时才会关联
+	XTest *PackageType `json:"-"`
+	// 是外部测试包时，为被测试的包
+	TestFor *PackageType `json:"-"`
 
 	importSet map[string]struct{}
+	// 以基础类型声明的具名类型，包含对应常量时才会成为Enums
+	enumCandidates []*EnumType
 }
 
+// 因为没有FileSet，所以解析结果中不包含位置信息，需要位置信息时请使用NewPackageTypeWithFileSet
 func NewPackageType(pkg *ast.Package) (*PackageType, error) {
+	return NewPackageTypeWithFileSet(nil, pkg)
+}
+
+// fSet需要是解析pkg时使用的FileSet
+func NewPackageTypeWithFileSet(fSet *token.FileSet, pkg *ast.Package) (*PackageType, error) {
 	var err error
 	pkgTyp := &PackageType{
+		FileSet: fSet,
 		Name:    pkg.Name,
 		Imports: make([]*ImportType, 0, 16),
 		Structs: make([]*StructType, 0, 64),
@@ -27,44 +67,260 @@ func NewPackageType(pkg *ast.Package) (*PackageType, error) {
 
 		importSet: make(map[string]struct{}, 32),
 	}
-	for _, astFile := range pkg.Files {
-		for _, decl := range astFile.Decls {
-			switch curDecl := decl.(type) {
-			case *ast.GenDecl:
-				err = pkgTyp.ParseGenDecl(curDecl)
-			case *ast.FuncDecl:
-				err = pkgTyp.ParseFuncDecl(curDecl)
-			case *ast.BadDecl:
+	// 按文件路径排序，保证同一个包的解析结果总是一致的，每个文件内的声明则保持源码中的顺序
+	fileNames := make([]string, 0, len(pkg.Files))
+	for fileName := range pkg.Files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		fileType, err := pkgTyp.NewFileType(fileName, pkg.Files[fileName])
+		if err != nil {
+			return nil, err
+		}
+		pkgTyp.Files = append(pkgTyp.Files, fileType)
+		if err := fileType.parseDecls(); err != nil {
+			return nil, err
+		}
+	}
+
+	pkgTyp.fillPositions()
+	pkgTyp.linkImports()
+	pkgTyp.evalConsts()
+	pkgTyp.collectEnums(pkgTyp.enumCandidates)
+
+	pkgTyp.attachMethods()
+
+	return pkgTyp, err
+}
+
+// 把Method统计到包内声明的对应类型中
+// 无法找到接受者的Method会记录到Diagnostics中
+func (this *PackageType) attachMethods() {
+	structMap := make(map[string]*StructType, len(this.Structs))
+	for _, structType := range this.Structs {
+		structMap[structType.Name] = structType
+	}
+	interfaceMap := make(map[string]*InterfaceType, len(this.Interfaces))
+	for _, interfaceType := range this.Interfaces {
+		interfaceMap[interfaceType.Name] = interfaceType
+	}
+	definedMap := make(map[string]*DefinedType, len(this.Defineds))
+	for _, definedType := range this.Defineds {
+		definedMap[definedType.Name] = definedType
+	}
 
+	for _, methodType := range this.Methods {
+		// 引入泛型以后需要注意，receiver 的名字的格式是
+		// StructName[T]，但是声明类型的名字的格式是 StructName[T any]
+		// 所以这里统一使用不带类型参数的名字
+		receiverName := methodType.GetRecvTypeName()
+		if definedType, ok := definedMap[receiverName]; ok && definedType.IsAlias {
+			// 接受者是别名时，方法属于别名指向的类型
+			aliasTarget := this.ResolveAlias(definedType.Type)
+			if aliasTarget.Kind != Ident {
+				this.addDiagnostic(methodType.Receiver.Pos, "method %s has invalid receiver: alias %s does not denote a type declared in package %s", methodType.Name, receiverName, this.Name)
+				continue
 			}
-			if err != nil {
-				return nil, err
+			receiverName = aliasTarget.Name
+		}
+		if structType, ok := structMap[receiverName]; ok {
+			structType.Methods = append(structType.Methods, methodType)
+		} else if definedType, ok := definedMap[receiverName]; ok {
+			definedType.Methods = append(definedType.Methods, methodType)
+		} else if _, ok := interfaceMap[receiverName]; ok {
+			this.addDiagnostic(methodType.Receiver.Pos, "method %s has invalid receiver: %s is an interface type", methodType.Name, receiverName)
+		} else {
+			this.addDiagnostic(methodType.Receiver.Pos, "method %s has unresolved receiver: %s is not declared in package %s", methodType.Name, receiverName, this.Name)
+		}
+	}
+}
+
+// 根据名字查找包内声明的DefinedType
+func (this *PackageType) GetDefinedType(name string) (*DefinedType, bool) {
+	for _, definedType := range this.Defineds {
+		if definedType.Name == name {
+			return definedType, true
+		}
+	}
+	return nil, false
+}
+
+// 获取_test.go文件中的测试函数，即以Test、Benchmark、Example、Fuzz开头的函数，包括外部测试包中的
+func (this *PackageType) GetTestFuncs() []*FuncType {
+	testFuncs := make([]*FuncType, 0, 16)
+	for _, pkgTyp := range []*PackageType{this, this.XTest} {
+		if pkgTyp == nil {
+			continue
+		}
+		for _, fileType := range pkgTyp.Files {
+			if !fileType.IsTest {
+				continue
+			}
+			for _, funcType := range fileType.Funcs {
+				if isTestFuncName(funcType.Name) {
+					testFuncs = append(testFuncs, funcType)
+				}
 			}
 		}
 	}
+	return testFuncs
+}
 
-	// 把Method统计到对应的Struct中
-	structMap := make(map[string]*StructType, len(pkgTyp.Structs))
-	for _, structType := range pkgTyp.Structs {
-		// 引入泛型以后需要注意，receiver 的名字的格式是
-		// StructName[T]，但是声明类型的名字的格式是 StructName[T any]
-		structMap[structType.GetRecvName()] = structType
-		// if strings.HasPrefix(structType.GetRecvName(), "UserGeneric") {
-		// 	log.Println(gjson.MustEncodeString(structType), structType.GetRecvName())
-		// }
+// 判断函数是否已经有对应的测试函数，按惯例匹配`TestName`
+// 方法以`Recv.Method`的形式传入，匹配`TestRecv_Method`
+func (this *PackageType) HasTest(name string) bool {
+	testName := "Test" + strings.Replace(name, ".", "_", 1)
+	for _, funcType := range this.GetTestFuncs() {
+		if funcType.Name == testName {
+			return true
+		}
+	}
+	return false
+}
+
+// 与`go test`的规则一致，前缀之后不能紧跟小写字母，例如`Testing`不是测试函数
+func isTestFuncName(name string) bool {
+	for _, prefix := range []string{"Test", "Benchmark", "Example", "Fuzz"} {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if len(name) == len(prefix) {
+			return true
+		}
+		r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+		return !unicode.IsLower(r)
 	}
-	// log.Println(structMap)
-	for _, methodType := range pkgTyp.Methods {
-		receiverName := strings.TrimLeft(methodType.Receiver.Type.GetDecl(), "*")
-		structType, ok := structMap[receiverName]
-		if !ok {
-			// log.Println(gjson.MustEncodeString(methodType))
-			return nil, fmt.Errorf("NewPackageType: unreslove method receiver.Name = %s", receiverName)
+	return false
+}
+
+// 沿着包内声明的别名找到最终指向的类型，例如`type StatusID = int8`时`StatusID`会被解析为`int8`
+// 对于指针会解析其指向的类型，不是别名时返回原类型
+func (this *PackageType) ResolveAlias(typeType *TypeType) *TypeType {
+	if typeType == nil {
+		return nil
+	}
+	if typeType.Kind == Star {
+		elemType := this.ResolveAlias(typeType.Elem)
+		if elemType == typeType.Elem {
+			return typeType
 		}
-		structType.Methods = append(structType.Methods, methodType)
+		return &TypeType{Kind: Star, Elem: elemType}
 	}
+	// 避免循环别名导致死循环
+	visited := make(map[string]struct{})
+	for typeType.Kind == Ident {
+		if _, ok := visited[typeType.Name]; ok {
+			break
+		}
+		visited[typeType.Name] = struct{}{}
+		definedType, ok := this.GetDefinedType(typeType.Name)
+		if !ok || !definedType.IsAlias {
+			break
+		}
+		typeType = definedType.Type
+	}
+	return typeType
+}
 
-	return pkgTyp, err
+// 遍历包内所有的FieldType和FuncType，包括匿名结构体、函数签名、匿名接口中的
+func (this *PackageType) walkFieldsAndFuncs(fieldFn func(*FieldType), funcFn func(*FuncType)) {
+	walkFields := func(fieldTypes []*FieldType) {
+		for _, fieldType := range fieldTypes {
+			fieldFn(fieldType)
+		}
+	}
+	walkFunc := func(funcType *FuncType) {
+		funcFn(funcType)
+		walkFields(funcType.TypeParams)
+		walkFields(funcType.Params)
+		walkFields(funcType.Results)
+	}
+
+	for _, structType := range this.Structs {
+		for _, fieldType := range structType.TypeParams {
+			fieldFn(&fieldType.FieldType)
+		}
+		for _, fieldType := range structType.Fields {
+			fieldFn(&fieldType.FieldType)
+		}
+	}
+	for _, interfaceType := range this.Interfaces {
+		walkFields(interfaceType.TypeParams)
+		for _, funcType := range interfaceType.Funcs {
+			walkFunc(&funcType.FuncType)
+		}
+	}
+	for _, definedType := range this.Defineds {
+		walkFields(definedType.TypeParams)
+	}
+	for _, funcType := range this.Funcs {
+		walkFunc(funcType)
+	}
+	for _, methodType := range this.Methods {
+		fieldFn(methodType.Receiver)
+		walkFunc(&methodType.FuncType)
+	}
+	this.walkTypeTypes(func(typeType *TypeType) bool {
+		walkFields(typeType.Params)
+		walkFields(typeType.Results)
+		for _, fieldType := range typeType.Fields {
+			fieldFn(&fieldType.FieldType)
+		}
+		for _, funcType := range typeType.Methods {
+			walkFunc(funcType)
+		}
+		return true
+	})
+}
+
+// 遍历包内声明中出现的所有TypeType，包括嵌套的类型
+func (this *PackageType) walkTypeTypes(fn func(*TypeType) bool) {
+	walkFields := func(fieldTypes []*FieldType) {
+		for _, fieldType := range fieldTypes {
+			fieldType.Type.walk(fn)
+		}
+	}
+	walkFunc := func(funcType *FuncType) {
+		walkFields(funcType.TypeParams)
+		walkFields(funcType.Params)
+		walkFields(funcType.Results)
+	}
+
+	for _, structType := range this.Structs {
+		for _, fieldType := range structType.TypeParams {
+			fieldType.Type.walk(fn)
+		}
+		for _, fieldType := range structType.Fields {
+			fieldType.Type.walk(fn)
+		}
+	}
+	for _, interfaceType := range this.Interfaces {
+		walkFields(interfaceType.TypeParams)
+		for _, funcType := range interfaceType.Funcs {
+			walkFunc(&funcType.FuncType)
+		}
+		for _, embedType := range interfaceType.Embeds {
+			embedType.walk(fn)
+		}
+	}
+	for _, definedType := range this.Defineds {
+		walkFields(definedType.TypeParams)
+		definedType.Type.walk(fn)
+	}
+	for _, funcType := range this.Funcs {
+		walkFunc(funcType)
+	}
+	for _, methodType := range this.Methods {
+		methodType.Receiver.Type.walk(fn)
+		walkFunc(&methodType.FuncType)
+	}
+	for _, valueType := range this.Consts {
+		valueType.Type.walk(fn)
+	}
+	for _, valueType := range this.Vars {
+		valueType.Type.walk(fn)
+	}
 }
 
 func (this *PackageType) ParseFuncDecl(funcDecl *ast.FuncDecl) error {
@@ -95,7 +351,7 @@ func (this *PackageType) ParseGenDecl(genDecl *ast.GenDecl) error {
 			// 以import声明的顶级变量
 			err = this.ParseImportSpec(genDecl, curSpec)
 		case *ast.ValueSpec:
-			// 以var声明的顶级变量
+			// 以const或者var声明的顶级变量
 			err = this.ParseValueSpec(genDecl, curSpec)
 		}
 		if err != nil {
@@ -106,6 +362,16 @@ func (this *PackageType) ParseGenDecl(genDecl *ast.GenDecl) error {
 }
 
 func (this *PackageType) ParseTypeSpec(astGenDecl *ast.GenDecl, typeSpec *ast.TypeSpec) error {
+	if typeSpec.Assign.IsValid() {
+		// 形如`type StatusID = int8`的别名，无论等号右侧是什么都统一作为DefinedType
+		definedType, err := this.NewDefinedType(astGenDecl, typeSpec)
+		if err != nil {
+			return err
+		}
+		this.Defineds = append(this.Defineds, definedType)
+		return nil
+	}
+
 	switch typeExpr := typeSpec.Type.(type) {
 	case *ast.StructType:
 		// 是type struct
@@ -120,36 +386,49 @@ func (this *PackageType) ParseTypeSpec(astGenDecl *ast.GenDecl, typeSpec *ast.Ty
 			return err
 		}
 		this.Interfaces = append(this.Interfaces, interfaceType)
-	case *ast.ArrayType:
-	case *ast.FuncType:
-	case *ast.MapType:
-	case *ast.ChanType:
-	case *ast.Ident:
-		// fmt.Println(typeExpr.Name)
+	case *ast.ArrayType, *ast.FuncType, *ast.MapType, *ast.ChanType, *ast.Ident,
+		*ast.SelectorExpr, *ast.StarExpr, *ast.IndexExpr, *ast.IndexListExpr, *ast.ParenExpr:
+		definedType, err := this.NewDefinedType(astGenDecl, typeSpec)
+		if err != nil {
+			return err
+		}
+		this.Defineds = append(this.Defineds, definedType)
+
+		if astIdent, ok := typeExpr.(*ast.Ident); ok && typeSpec.TypeParams == nil {
+			// 形如`type StatusID int8`，可能是枚举
+			enumType, err := this.NewEnumType(astGenDecl, typeSpec, astIdent)
+			if err != nil {
+				return err
+			}
+			this.enumCandidates = append(this.enumCandidates, enumType)
+		}
 	default:
 		return fmt.Errorf("PackageType.ParseTypeSpec()未处理的*ast.TypeSpec=%T", typeExpr)
 	}
 	return nil
 }
 
+// 以别名和路径去重，同一个路径以不同的别名导入时会保留多个
 func (this *PackageType) ParseImportSpec(astGenDecl *ast.GenDecl, importSpec *ast.ImportSpec) error {
-	if _, ok := this.importSet[importSpec.Path.Value]; !ok {
-		this.Imports = append(this.Imports, &ImportType{
-			Alias: func() string {
-				if importSpec.Name != nil {
-					return importSpec.Name.Name
-				}
-				return ""
-			}(),
-			// delete the '"' prefix and sufix
-			Name: strings.Trim(importSpec.Path.Value, `"`),
-		})
-		this.importSet[importSpec.Path.Value] = struct{}{}
+	importType := NewImportType(importSpec)
+	importKey := importType.Alias + " " + importType.Name
+	if _, ok := this.importSet[importKey]; !ok {
+		this.Imports = append(this.Imports, importType)
+		this.importSet[importKey] = struct{}{}
 	}
 	return nil
 }
 
 func (this *PackageType) ParseValueSpec(astGenDecl *ast.GenDecl, valueSpec *ast.ValueSpec) error {
+	valueTypes, err := this.NewValueTypes(astGenDecl, valueSpec)
+	if err != nil {
+		return err
+	}
+	if astGenDecl.Tok == token.CONST {
+		this.Consts = append(this.Consts, valueTypes...)
+	} else {
+		this.Vars = append(this.Vars, valueTypes...)
+	}
 	return nil
 }
 
@@ -158,14 +437,20 @@ func (this *PackageType) String() string {
 	sb.WriteString("package " + this.Name + "\n\n")
 	sb.WriteString("import (\n")
 	for _, importType := range this.Imports {
-		if importType.Alias != "" {
-			sb.WriteString("\t" + importType.Alias + ` "` + importType.Name + `"` + "\n")
-		} else {
-			sb.WriteString("\t" + ` "` + importType.Name + `"` + "\n")
-		}
+		sb.WriteString("\t" + importType.GetDecl() + "\n")
 	}
 	sb.WriteString(")\n\n")
 
+	for _, valueType := range this.Consts {
+		sb.WriteString(valueType.GetDecl() + "\n")
+	}
+	for _, valueType := range this.Vars {
+		sb.WriteString(valueType.GetDecl() + "\n")
+	}
+	if len(this.Consts)+len(this.Vars) > 0 {
+		sb.WriteString("\n")
+	}
+
 	for _, interfaceType := range this.Interfaces {
 		sb.WriteString(interfaceType.String() + "\n")
 	}
@@ -174,6 +459,10 @@ func (this *PackageType) String() string {
 		sb.WriteString(structType.GetDecl() + "\n")
 	}
 
+	for _, definedType := range this.Defineds {
+		sb.WriteString(definedType.GetDecl() + "\n")
+	}
+
 	for _, methodType := range this.Methods {
 		sb.WriteString(methodType.String() + "\n")
 	}
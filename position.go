@@ -0,0 +1,112 @@
+package aster
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// 用于描述元素在源码中的位置，与token.Position类似，但是同时记录了结束的位置
+type Position struct {
+	Filename  string `json:",omitempty"`
+	Offset    int    `json:",omitempty"` // 从0开始的字节偏移
+	Line      int    `json:",omitempty"` // 从1开始的行号
+	Column    int    `json:",omitempty"` // 从1开始的列号（按字节计算）
+	EndOffset int    `json:",omitempty"`
+	EndLine   int    `json:",omitempty"`
+	EndColumn int    `json:",omitempty"`
+}
+
+func newPosition(fSet *token.FileSet, pos, end token.Pos) Position {
+	if fSet == nil || !pos.IsValid() {
+		return Position{}
+	}
+	startPos := fSet.Position(pos)
+	position := Position{
+		Filename: startPos.Filename,
+		Offset:   startPos.Offset,
+		Line:     startPos.Line,
+		Column:   startPos.Column,
+	}
+	if end.IsValid() {
+		endPos := fSet.Position(end)
+		position.EndOffset = endPos.Offset
+		position.EndLine = endPos.Line
+		position.EndColumn = endPos.Column
+	}
+	return position
+}
+
+func newNodePosition(fSet *token.FileSet, astNode ast.Node) Position {
+	if astNode == nil {
+		return Position{}
+	}
+	return newPosition(fSet, astNode.Pos(), astNode.End())
+}
+
+// 是否是有效的位置
+func (this Position) IsValid() bool {
+	return this.Line > 0
+}
+
+// 形如`model.go:12:2`，与token.Position的格式一致
+func (this Position) String() string {
+	s := this.Filename
+	if this.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d:%d", this.Line, this.Column)
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
+}
+
+// 根据记录的AST节点填充包内所有元素的位置，没有FileSet时不做任何处理
+func (this *PackageType) fillPositions() {
+	fSet := this.FileSet
+	if fSet == nil {
+		return
+	}
+
+	for _, fileType := range this.Files {
+		fileType.Pos = newNodePosition(fSet, fileType.astFile)
+		for _, importType := range fileType.Imports {
+			importType.Pos = newNodePosition(fSet, importType.astSpec)
+		}
+	}
+	// 去重后保留的是最先出现的import的位置
+	for _, importType := range this.Imports {
+		importType.Pos = newNodePosition(fSet, importType.astSpec)
+	}
+	for _, structType := range this.Structs {
+		structType.Pos = newNodePosition(fSet, structType.astSpec)
+	}
+	for _, interfaceType := range this.Interfaces {
+		interfaceType.Pos = newNodePosition(fSet, interfaceType.astSpec)
+	}
+	for _, definedType := range this.Defineds {
+		definedType.Pos = newNodePosition(fSet, definedType.astSpec)
+	}
+	for _, valueType := range this.Consts {
+		valueType.Pos = newPosition(fSet, valueType.astName.Pos(), valueType.astName.End())
+	}
+	for _, valueType := range this.Vars {
+		valueType.Pos = newPosition(fSet, valueType.astName.Pos(), valueType.astName.End())
+	}
+	for _, enumType := range this.enumCandidates {
+		enumType.Pos = newNodePosition(fSet, enumType.astSpec)
+	}
+
+	this.walkTypeTypes(func(typeType *TypeType) bool {
+		typeType.Pos = newNodePosition(fSet, typeType.astExpr)
+		return true
+	})
+	this.walkFieldsAndFuncs(func(fieldType *FieldType) {
+		fieldType.fillPosition(fSet)
+	}, func(funcType *FuncType) {
+		funcType.Pos = newNodePosition(fSet, funcType.astNode)
+	})
+}
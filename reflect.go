@@ -0,0 +1,696 @@
+package aster
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+)
+
+// 因为TypeType、StructType等结构本身已经有Name、Kind等导出字段，无法再声明同名的方法，
+// 所以通过以下的视图来实现Type接口，可以通过AsType()或者PackageType.TypeOf()获取。
+
+var (
+	_ Type = (*typeTypeView)(nil)
+	_ Type = (*structTypeView)(nil)
+	_ Type = (*interfaceTypeView)(nil)
+	_ Type = (*definedTypeView)(nil)
+	_ Type = (*funcTypeView)(nil)
+)
+
+// 获取TypeType对应的Type，会在当前包内解析具名类型的引用
+// 例如`*User`的Elem()会返回User对应的StructType的Type
+func (this *PackageType) TypeOf(typeType *TypeType) Type {
+	if typeType == nil {
+		return nil
+	}
+	typeType = this.ResolveAlias(typeType)
+	if typeType.Kind == Ident {
+		if structType, ok := this.GetStructType(typeType.Name); ok {
+			return structType.AsType()
+		}
+		if interfaceType, ok := this.GetInterfaceType(typeType.Name); ok {
+			return interfaceType.AsType()
+		}
+		if definedType, ok := this.GetDefinedType(typeType.Name); ok {
+			return definedType.AsType()
+		}
+	}
+	return newTypeTypeView(this, typeType)
+}
+
+// 获取当前类型对应的Type，因为没有所属包的信息，所以不会解析具名类型的引用
+// 需要解析引用时请使用PackageType.TypeOf()
+func (this *TypeType) AsType() Type {
+	return newTypeTypeView(nil, this)
+}
+
+func (this *StructType) AsType() Type {
+	view := &structTypeView{structType: this}
+	view.self = view
+	return view
+}
+
+func (this *InterfaceType) AsType() Type {
+	view := &interfaceTypeView{interfaceType: this}
+	view.self = view
+	return view
+}
+
+func (this *DefinedType) AsType() Type {
+	return &definedTypeView{definedType: this}
+}
+
+// 获取函数签名对应的Type，因为没有所属包的信息，所以不会解析参数中具名类型的引用
+// 需要解析引用时请使用PackageType.FuncTypeOf()
+func (this *FuncType) AsType() Type {
+	return newFuncTypeView(nil, this)
+}
+
+// 获取函数签名对应的Type，会在当前包内解析参数和返回值中具名类型的引用
+func (this *PackageType) FuncTypeOf(funcType *FuncType) Type {
+	return newFuncTypeView(this, funcType)
+}
+
+// 根据名字查找包内声明的StructType
+func (this *PackageType) GetStructType(name string) (*StructType, bool) {
+	for _, structType := range this.Structs {
+		if structType.Name == name {
+			return structType, true
+		}
+	}
+	return nil, false
+}
+
+// 根据名字查找包内声明的InterfaceType
+func (this *PackageType) GetInterfaceType(name string) (*InterfaceType, bool) {
+	for _, interfaceType := range this.Interfaces {
+		if interfaceType.Name == name {
+			return interfaceType, true
+		}
+	}
+	return nil, false
+}
+
+// 在可能为nil的包内解析类型
+func typeOf(pkgType *PackageType, typeType *TypeType) Type {
+	if pkgType == nil {
+		return typeType.AsType()
+	}
+	return pkgType.TypeOf(typeType)
+}
+
+// 对不支持的操作panic，行为与reflect保持一致
+// 各视图嵌入panicType后只需要实现自己支持的方法
+type panicType struct {
+	self Type
+}
+
+func (this panicType) panicf(method string) {
+	panic(fmt.Sprintf("aster: %s of unsupported type %s (kind %d)", method, this.self.String(), this.self.Kind()))
+}
+
+func (this panicType) Method(int) MethodType {
+	this.panicf("Method")
+	return MethodType{}
+}
+
+func (this panicType) MethodByName(string) (MethodType, bool) {
+	return MethodType{}, false
+}
+
+func (this panicType) NumMethod() int {
+	return 0
+}
+
+func (this panicType) ChanDir() ChanDir {
+	this.panicf("ChanDir")
+	return 0
+}
+
+func (this panicType) Elem() Type {
+	this.panicf("Elem")
+	return nil
+}
+
+func (this panicType) Len() int {
+	this.panicf("Len")
+	return 0
+}
+
+func (this panicType) Field(int) StructFieldType {
+	this.panicf("Field")
+	return StructFieldType{}
+}
+
+func (this panicType) FieldByIndex([]int) StructFieldType {
+	this.panicf("FieldByIndex")
+	return StructFieldType{}
+}
+
+func (this panicType) FieldByName(string) (StructFieldType, bool) {
+	this.panicf("FieldByName")
+	return StructFieldType{}, false
+}
+
+func (this panicType) In(int) Type {
+	this.panicf("In")
+	return nil
+}
+
+func (this panicType) IsVariadic() bool {
+	this.panicf("IsVariadic")
+	return false
+}
+
+func (this panicType) Key() Type {
+	this.panicf("Key")
+	return nil
+}
+
+func (this panicType) NumField() int {
+	this.panicf("NumField")
+	return 0
+}
+
+func (this panicType) NumIn() int {
+	this.panicf("NumIn")
+	return 0
+}
+
+func (this panicType) NumOut() int {
+	this.panicf("NumOut")
+	return 0
+}
+
+func (this panicType) Out(int) Type {
+	this.panicf("Out")
+	return nil
+}
+
+// 类型表达式，例如`*User`、`[]int`、`map[string]int`
+type typeTypeView struct {
+	panicType
+	pkg *PackageType
+	typ *TypeType
+}
+
+func newTypeTypeView(pkgType *PackageType, typeType *TypeType) *typeTypeView {
+	view := &typeTypeView{pkg: pkgType, typ: typeType}
+	view.self = view
+	return view
+}
+
+func (this *typeTypeView) Name() string {
+	switch this.typ.Kind {
+	case Ident, Selector:
+		return this.typ.Name
+	default:
+		return ""
+	}
+}
+
+func (this *typeTypeView) Kind() Kind {
+	return this.typ.Kind
+}
+
+func (this *typeTypeView) Elem() Type {
+	switch this.typ.Kind {
+	case Star, Array, Slice, Ellipsis, Chan, Map:
+		return typeOf(this.pkg, this.typ.Elem)
+	default:
+		return this.panicType.Elem()
+	}
+}
+
+func (this *typeTypeView) ChanDir() ChanDir {
+	if this.typ.Kind != Chan {
+		return this.panicType.ChanDir()
+	}
+	return this.typ.ChanDir
+}
+
+func (this *typeTypeView) Len() int {
+	if this.typ.Kind != Array {
+		return this.panicType.Len()
+	}
+	return int(this.typ.LenValue)
+}
+
+func (this *typeTypeView) Key() Type {
+	if this.typ.Kind != Map {
+		return this.panicType.Key()
+	}
+	return typeOf(this.pkg, this.typ.Key)
+}
+
+// 匿名接口的方法，或者指向具名类型的指针的方法集（包含接受者为T和*T的方法）
+func (this *typeTypeView) methods() []*MethodType {
+	switch this.typ.Kind {
+	case Interface:
+		return interfaceMethods(this.pkg, this.typ.Methods, this.typ.Embeds)
+	case Star:
+		switch elemView := typeOf(this.pkg, this.typ.Elem).(type) {
+		case *structTypeView:
+			return exportedMethods(elemView.structType.Methods, true)
+		case *definedTypeView:
+			return exportedMethods(elemView.definedType.Methods, true)
+		}
+	}
+	return nil
+}
+
+func (this *typeTypeView) Method(i int) MethodType {
+	return *this.methods()[i]
+}
+
+func (this *typeTypeView) MethodByName(name string) (MethodType, bool) {
+	return methodByName(this.methods(), name)
+}
+
+func (this *typeTypeView) NumMethod() int {
+	return len(this.methods())
+}
+
+func (this *typeTypeView) Field(i int) StructFieldType {
+	if this.typ.Kind != Struct {
+		return this.panicType.Field(i)
+	}
+	return *this.typ.Fields[i]
+}
+
+func (this *typeTypeView) FieldByIndex(index []int) StructFieldType {
+	if this.typ.Kind != Struct {
+		return this.panicType.FieldByIndex(index)
+	}
+	return fieldByIndex(this.pkg, this, index)
+}
+
+func (this *typeTypeView) FieldByName(name string) (StructFieldType, bool) {
+	if this.typ.Kind != Struct {
+		return this.panicType.FieldByName(name)
+	}
+	return fieldByName(this.pkg, this, name)
+}
+
+func (this *typeTypeView) NumField() int {
+	if this.typ.Kind != Struct {
+		return this.panicType.NumField()
+	}
+	return len(this.typ.Fields)
+}
+
+func (this *typeTypeView) In(i int) Type {
+	if this.typ.Kind != Func {
+		return this.panicType.In(i)
+	}
+	return typeOf(this.pkg, this.typ.Params[i].Type)
+}
+
+func (this *typeTypeView) IsVariadic() bool {
+	if this.typ.Kind != Func {
+		return this.panicType.IsVariadic()
+	}
+	return isVariadic(this.typ.Params)
+}
+
+func (this *typeTypeView) NumIn() int {
+	if this.typ.Kind != Func {
+		return this.panicType.NumIn()
+	}
+	return len(this.typ.Params)
+}
+
+func (this *typeTypeView) NumOut() int {
+	if this.typ.Kind != Func {
+		return this.panicType.NumOut()
+	}
+	return len(this.typ.Results)
+}
+
+func (this *typeTypeView) Out(i int) Type {
+	if this.typ.Kind != Func {
+		return this.panicType.Out(i)
+	}
+	return typeOf(this.pkg, this.typ.Results[i].Type)
+}
+
+func (this *typeTypeView) String() string {
+	return this.typ.GetDecl()
+}
+
+// 最后一个参数是否是可变参数
+func isVariadic(params []*FieldType) bool {
+	return len(params) > 0 && params[len(params)-1].Type.Kind == Ellipsis
+}
+
+// 按名字排序的导出方法，与reflect的方法集顺序保持一致
+// 与Go的方法集规则一致，T的方法集不包含接受者为*T的方法，withPtr为true时返回*T的方法集
+func exportedMethods(methodTypes []*MethodType, withPtr bool) []*MethodType {
+	exported := make([]*MethodType, 0, len(methodTypes))
+	for _, methodType := range methodTypes {
+		if !withPtr && methodType.Receiver != nil && methodType.Receiver.Type.Kind == Star {
+			continue
+		}
+		if ast.IsExported(methodType.Name) {
+			exported = append(exported, methodType)
+		}
+	}
+	sort.SliceStable(exported, func(i, j int) bool {
+		return exported[i].Name < exported[j].Name
+	})
+	return exported
+}
+
+// 按名字排序并去重的接口方法，包括嵌入的接口中的方法，接口的方法没有接受者
+// 嵌入的类型约束（例如`~int | ~string`）以及无法在包内解析的接口不提供方法
+func interfaceMethods(pkgType *PackageType, funcTypes []*FuncType, embeds []*TypeType) []*MethodType {
+	methodMap := make(map[string]*MethodType, len(funcTypes))
+	collectInterfaceMethods(pkgType, funcTypes, embeds, methodMap, make(map[*InterfaceType]bool))
+	methodTypes := make([]*MethodType, 0, len(methodMap))
+	for _, methodType := range methodMap {
+		methodTypes = append(methodTypes, methodType)
+	}
+	sort.Slice(methodTypes, func(i, j int) bool {
+		return methodTypes[i].Name < methodTypes[j].Name
+	})
+	return methodTypes
+}
+
+// visited用于避免非法的循环嵌入导致死循环
+func collectInterfaceMethods(pkgType *PackageType, funcTypes []*FuncType, embeds []*TypeType, methodMap map[string]*MethodType, visited map[*InterfaceType]bool) {
+	for _, funcType := range funcTypes {
+		if _, ok := methodMap[funcType.Name]; !ok {
+			methodMap[funcType.Name] = &MethodType{FuncType: *funcType}
+		}
+	}
+	for _, embedType := range embeds {
+		embedView := typeOf(pkgType, embedType)
+		// 以接口为底层类型的具名类型，例如`type RW io.ReadWriter`
+		visitedDefined := make(map[*DefinedType]bool)
+		for {
+			definedView, ok := embedView.(*definedTypeView)
+			if !ok || visitedDefined[definedView.definedType] {
+				break
+			}
+			visitedDefined[definedView.definedType] = true
+			embedView = definedView.underlying()
+		}
+		switch view := embedView.(type) {
+		case *interfaceTypeView:
+			if visited[view.interfaceType] {
+				continue
+			}
+			visited[view.interfaceType] = true
+			collectInterfaceMethods(view.interfaceType.PackageType, view.interfaceType.getFuncTypes(), view.interfaceType.Embeds, methodMap, visited)
+		case *typeTypeView:
+			if view.typ.Kind == Interface {
+				collectInterfaceMethods(view.pkg, view.typ.Methods, view.typ.Embeds, methodMap, visited)
+			}
+		}
+	}
+}
+
+// 依次对每一层调用Field，遇到指针时使用其指向的类型
+func fieldByIndex(pkgType *PackageType, structType Type, index []int) StructFieldType {
+	var field StructFieldType
+	for i, x := range index {
+		if i > 0 {
+			structType = typeOf(pkgType, field.Type)
+			if structType.Kind() == Star {
+				structType = structType.Elem()
+			}
+		}
+		field = structType.Field(x)
+	}
+	return field
+}
+
+// 与reflect一致，会在匿名嵌入的结构体中按广度优先查找
+func fieldByName(pkgType *PackageType, structType Type, name string) (StructFieldType, bool) {
+	visited := make(map[string]bool)
+	current := []Type{structType}
+	for len(current) > 0 {
+		next := make([]Type, 0)
+		for _, curType := range current {
+			if visited[curType.String()] {
+				continue
+			}
+			visited[curType.String()] = true
+			for i := 0; i < curType.NumField(); i++ {
+				field := curType.Field(i)
+				if field.Name == name {
+					return field, true
+				}
+				if field.Name != "" {
+					continue
+				}
+				// 匿名嵌入的字段，字段名就是类型名
+				embedded := typeOf(pkgType, field.Type)
+				if embedded.Kind() == Star {
+					embedded = embedded.Elem()
+				}
+				if embedded.Name() == name {
+					return field, true
+				}
+				if embedded.Kind() == Struct {
+					next = append(next, embedded)
+				}
+			}
+		}
+		current = next
+	}
+	return StructFieldType{}, false
+}
+
+func methodByName(methodTypes []*MethodType, name string) (MethodType, bool) {
+	for _, methodType := range methodTypes {
+		if methodType.Name == name {
+			return *methodType, true
+		}
+	}
+	return MethodType{}, false
+}
+
+// 以type声明的结构体
+type structTypeView struct {
+	panicType
+	structType *StructType
+}
+
+func (this *structTypeView) Method(i int) MethodType {
+	return *exportedMethods(this.structType.Methods, false)[i]
+}
+
+func (this *structTypeView) MethodByName(name string) (MethodType, bool) {
+	return methodByName(exportedMethods(this.structType.Methods, false), name)
+}
+
+func (this *structTypeView) NumMethod() int {
+	return len(exportedMethods(this.structType.Methods, false))
+}
+
+func (this *structTypeView) Name() string {
+	return this.structType.Name
+}
+
+func (this *structTypeView) Kind() Kind {
+	return Struct
+}
+
+func (this *structTypeView) Field(i int) StructFieldType {
+	return *this.structType.Fields[i]
+}
+
+func (this *structTypeView) FieldByIndex(index []int) StructFieldType {
+	return fieldByIndex(this.structType.PackageType, this, index)
+}
+
+func (this *structTypeView) FieldByName(name string) (StructFieldType, bool) {
+	return fieldByName(this.structType.PackageType, this, name)
+}
+
+func (this *structTypeView) NumField() int {
+	return len(this.structType.Fields)
+}
+
+func (this *structTypeView) String() string {
+	return this.structType.GetRecvName()
+}
+
+// 以type声明的接口
+type interfaceTypeView struct {
+	panicType
+	interfaceType *InterfaceType
+}
+
+func (this *interfaceTypeView) methods() []*MethodType {
+	return interfaceMethods(this.interfaceType.PackageType, this.interfaceType.getFuncTypes(), this.interfaceType.Embeds)
+}
+
+func (this *interfaceTypeView) Method(i int) MethodType {
+	return *this.methods()[i]
+}
+
+func (this *interfaceTypeView) MethodByName(name string) (MethodType, bool) {
+	return methodByName(this.methods(), name)
+}
+
+func (this *interfaceTypeView) NumMethod() int {
+	return len(this.methods())
+}
+
+func (this *interfaceTypeView) Name() string {
+	return this.interfaceType.Name
+}
+
+func (this *interfaceTypeView) Kind() Kind {
+	return Interface
+}
+
+func (this *interfaceTypeView) String() string {
+	return this.interfaceType.Name
+}
+
+// 以type声明的非struct、非interface类型
+// 除了名字和方法以外，其他行为都与底层类型一致
+type definedTypeView struct {
+	definedType *DefinedType
+}
+
+func (this *definedTypeView) underlying() Type {
+	return typeOf(this.definedType.PackageType, this.definedType.Type)
+}
+
+// 底层类型是接口时（例如`type RW io.ReadWriter`），方法集就是接口的方法集
+func (this *definedTypeView) methods() []*MethodType {
+	if underlying := this.underlying(); underlying.Kind() == Interface {
+		methodTypes := make([]*MethodType, 0, underlying.NumMethod())
+		for i := 0; i < underlying.NumMethod(); i++ {
+			methodType := underlying.Method(i)
+			methodTypes = append(methodTypes, &methodType)
+		}
+		return methodTypes
+	}
+	return exportedMethods(this.definedType.Methods, false)
+}
+
+func (this *definedTypeView) Method(i int) MethodType {
+	return *this.methods()[i]
+}
+
+func (this *definedTypeView) MethodByName(name string) (MethodType, bool) {
+	return methodByName(this.methods(), name)
+}
+
+func (this *definedTypeView) NumMethod() int {
+	return len(this.methods())
+}
+
+func (this *definedTypeView) Name() string {
+	return this.definedType.Name
+}
+
+func (this *definedTypeView) Kind() Kind {
+	return this.underlying().Kind()
+}
+
+func (this *definedTypeView) Elem() Type {
+	return this.underlying().Elem()
+}
+
+func (this *definedTypeView) ChanDir() ChanDir {
+	return this.underlying().ChanDir()
+}
+
+func (this *definedTypeView) Len() int {
+	return this.underlying().Len()
+}
+
+func (this *definedTypeView) Field(i int) StructFieldType {
+	return this.underlying().Field(i)
+}
+
+func (this *definedTypeView) FieldByIndex(index []int) StructFieldType {
+	return this.underlying().FieldByIndex(index)
+}
+
+func (this *definedTypeView) FieldByName(name string) (StructFieldType, bool) {
+	return this.underlying().FieldByName(name)
+}
+
+func (this *definedTypeView) In(i int) Type {
+	return this.underlying().In(i)
+}
+
+func (this *definedTypeView) IsVariadic() bool {
+	return this.underlying().IsVariadic()
+}
+
+func (this *definedTypeView) Key() Type {
+	return this.underlying().Key()
+}
+
+func (this *definedTypeView) NumField() int {
+	return this.underlying().NumField()
+}
+
+func (this *definedTypeView) NumIn() int {
+	return this.underlying().NumIn()
+}
+
+func (this *definedTypeView) NumOut() int {
+	return this.underlying().NumOut()
+}
+
+func (this *definedTypeView) Out(i int) Type {
+	return this.underlying().Out(i)
+}
+
+func (this *definedTypeView) String() string {
+	return this.definedType.GetRecvName()
+}
+
+// 函数或者方法的签名
+type funcTypeView struct {
+	panicType
+	pkg      *PackageType
+	funcType *FuncType
+}
+
+func newFuncTypeView(pkgType *PackageType, funcType *FuncType) *funcTypeView {
+	view := &funcTypeView{pkg: pkgType, funcType: funcType}
+	view.self = view
+	return view
+}
+
+func (this *funcTypeView) Name() string {
+	return ""
+}
+
+func (this *funcTypeView) Kind() Kind {
+	return Func
+}
+
+func (this *funcTypeView) In(i int) Type {
+	return typeOf(this.pkg, this.funcType.Params[i].Type)
+}
+
+func (this *funcTypeView) IsVariadic() bool {
+	return isVariadic(this.funcType.Params)
+}
+
+func (this *funcTypeView) NumIn() int {
+	return len(this.funcType.Params)
+}
+
+func (this *funcTypeView) NumOut() int {
+	return len(this.funcType.Results)
+}
+
+func (this *funcTypeView) Out(i int) Type {
+	return typeOf(this.pkg, this.funcType.Results[i].Type)
+}
+
+func (this *funcTypeView) String() string {
+	return this.funcType.GetSignature()
+}
@@ -14,6 +14,9 @@ type StructType struct {
 	Fields     []*StructFieldType `json:",omitempty"`
 	Methods    []*MethodType      `json:",omitempty"`
 	Docs       []Comment          `json:",omitempty"`
+	Pos        Position           `json:",omitempty"`
+
+	astSpec *ast.TypeSpec
 }
 
 func (pkgType *PackageType) NewStructType(astGenDecl *ast.GenDecl, typeSpec *ast.TypeSpec, astStructType *ast.StructType) (*StructType, error) {
@@ -22,6 +25,7 @@ func (pkgType *PackageType) NewStructType(astGenDecl *ast.GenDecl, typeSpec *ast
 
 		Name:    typeSpec.Name.Name,
 		Methods: make([]*MethodType, 0, 16),
+		astSpec: typeSpec,
 	}
 
 	if typeSpec.TypeParams != nil {
@@ -0,0 +1,98 @@
+package defined
+
+import (
+	"context"
+	"time"
+)
+
+const HashSize = 32
+
+// ID列表
+type IDs []int64
+
+type UUID [16]byte
+
+type Hash [HashSize]byte
+
+type Buffer [2 * HashSize]byte
+
+func (ids IDs) Len() int {
+	return len(ids)
+}
+
+type (
+	// 处理函数
+	Handler func(ctx context.Context) error
+
+	Labels map[string]string
+
+	Events chan int
+
+	EventSource <-chan int
+
+	JobSink chan<- string
+
+	EventSources chan (<-chan int)
+
+	Status int
+
+	Timeout time.Duration
+
+	List[T any] []T
+)
+
+func (s Status) String() string {
+	return "status"
+}
+
+func (s *Status) Set(v int) {
+	*s = Status(v)
+}
+
+func (l *List[T]) Push(v T) {
+	*l = append(*l, v)
+}
+
+type Hooks struct {
+	OnChange func(old, new *Status) error
+	Format   func(format string, args ...any) (n int, err error)
+	OnClose  func()
+}
+
+type Config struct {
+	DB struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	Logger interface {
+		Printf(format string, args ...interface{})
+		Flush() error
+	}
+	Empty struct{}
+	Extra interface{}
+}
+
+type Number interface {
+	~int | ~int64 | float64
+}
+
+type Reader interface {
+	Read(p []byte) (n int, err error)
+}
+
+type ReadCloser interface {
+	Reader
+	Close() error
+	Read(p []byte) (n int, err error)
+}
+
+// 底层类型是接口的具名类型
+type RC ReadCloser
+
+func Sum[T Number](values ...T) T {
+	var sum T
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
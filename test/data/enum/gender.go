@@ -0,0 +1,34 @@
+package enum
+
+import "runtime"
+
+// 性别
+type GenderID int8
+
+const (
+	// 未知
+	GenderUnknown GenderID = iota
+	GenderMale             // 男
+	GenderFemale           // 女
+)
+
+// 权限位
+type Perm uint32
+
+const (
+	PermRead Perm = 1 << iota
+	PermWrite
+	_
+	PermAdmin
+	PermAll = PermRead | PermWrite | PermAdmin
+)
+
+// 平台
+type Platform string
+
+const (
+	PlatformIOS     Platform = "ios"
+	PlatformAndroid Platform = "android"
+	// 引用了其他包的常量，无法求值
+	PlatformHost Platform = Platform(runtime.GOOS)
+)
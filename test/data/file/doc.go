@@ -0,0 +1,20 @@
+// Copyright 2022 szyhf. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+
+// Package file 用于测试文件级别的信息
+package file
+
+type Doc struct {
+	Name string
+}
+
+func NewDoc() *Doc {
+	return &Doc{}
+}
+
+func (d *Doc) String() string {
+	return d.Name
+}
@@ -0,0 +1,7 @@
+package file
+
+type Plain interface {
+	String() string
+}
+
+const PlainName = "plain"
@@ -0,0 +1,12 @@
+package imports
+
+import (
+	eu "github.com/szyhf/go-aster/test/data/enum"
+)
+
+type Alias struct {
+	Status eu.StatusID
+}
+
+// 其他包中类型的别名
+type Gender = eu.GenderID
@@ -0,0 +1,6 @@
+package imports
+
+import (
+	// 用于嵌入静态文件
+	_ "embed" // embed
+)
@@ -0,0 +1,10 @@
+package imports
+
+import (
+	. "github.com/szyhf/go-aster/test/data/enum"
+)
+
+type Dot struct {
+	Platform Platform
+	Plain    *Plain
+}
@@ -0,0 +1,11 @@
+package imports
+
+import (
+	"github.com/szyhf/go-aster"
+	"github.com/szyhf/go-aster/test/data/enum"
+)
+
+type Plain struct {
+	Gender enum.GenderID
+	Type   *aster.TypeType
+}
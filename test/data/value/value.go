@@ -0,0 +1,33 @@
+package value
+
+import (
+	"errors"
+	"time"
+)
+
+// 默认的超时时间
+const DefaultTimeout time.Duration = 3 * time.Second
+
+const (
+	// 最大重试次数
+	MaxRetry = 3
+	Prefix   = "aster" // 前缀
+)
+
+// 除法的结果取决于操作数的类型
+const (
+	Ratio     float64 = 3
+	HalfRatio         = Ratio / 2
+	Step      int     = 7
+	HalfStep          = Step / 2.0
+)
+
+// 找不到记录时返回的错误
+var ErrNotFound = errors.New("not found")
+
+var (
+	DefaultName  string
+	MinID, MaxID int64 = 1, 1 << 20
+	// 共用一个多返回值的表达式
+	StartAt, StartErr = time.Parse(time.RFC3339, "2022-01-01T00:00:00Z")
+)
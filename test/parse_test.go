@@ -1,7 +1,15 @@
 package aster
 
 import (
+	"go/build"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	aster "github.com/szyhf/go-aster"
@@ -67,3 +75,1171 @@ func TestParseStruct(t *testing.T) {
 		}
 	}
 }
+
+func TestParseValue(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./data/value", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+
+	expectConsts := map[string]string{
+		"DefaultTimeout": "const DefaultTimeout time.Duration = 3 * time.Second",
+		"MaxRetry":       "const MaxRetry = 3",
+		"Prefix":         `const Prefix = "aster"`,
+		"Ratio":          "const Ratio float64 = 3",
+		"HalfRatio":      "const HalfRatio = Ratio / 2",
+		"Step":           "const Step int = 7",
+		"HalfStep":       "const HalfStep = Step / 2.0",
+	}
+	expectConstValues := map[string]float64{"HalfRatio": 1.5, "HalfStep": 3}
+	if len(curPkgType.Consts) != len(expectConsts) {
+		t.Fatalf("常量数量不符合预期：%d", len(curPkgType.Consts))
+	}
+	for _, valueTyp := range curPkgType.Consts {
+		if !valueTyp.IsConst {
+			t.Fatalf("常量%s没有标记为const", valueTyp.Name)
+		}
+		if valueTyp.GetDecl() != expectConsts[valueTyp.Name] {
+			t.Fatalf("常量%s声明不符合预期：%s", valueTyp.Name, valueTyp.GetDecl())
+		}
+		if expValue, ok := expectConstValues[valueTyp.Name]; ok {
+			if val, _ := constant.Float64Val(valueTyp.ConstValue); valueTyp.ConstValue == nil || val != expValue {
+				t.Fatalf("常量%s的值不符合预期：%s", valueTyp.Name, valueTyp.GetConstValue())
+			}
+		}
+	}
+
+	expectVars := map[string]string{
+		"ErrNotFound": `var ErrNotFound = errors.New("not found")`,
+		"DefaultName": "var DefaultName string",
+		"MinID":       "var MinID int64 = 1",
+		"MaxID":       "var MaxID int64 = 1 << 20",
+		"StartAt":     `var StartAt, _ = time.Parse(time.RFC3339, "2022-01-01T00:00:00Z")`,
+		"StartErr":    `var _, StartErr = time.Parse(time.RFC3339, "2022-01-01T00:00:00Z")`,
+	}
+	if len(curPkgType.Vars) != len(expectVars) {
+		t.Fatalf("变量数量不符合预期：%d", len(curPkgType.Vars))
+	}
+	for _, valueTyp := range curPkgType.Vars {
+		if valueTyp.IsConst {
+			t.Fatalf("变量%s被标记为const", valueTyp.Name)
+		}
+		if valueTyp.GetDecl() != expectVars[valueTyp.Name] {
+			t.Fatalf("变量%s声明不符合预期：%s", valueTyp.Name, valueTyp.GetDecl())
+		}
+		if valueTyp.Name == "ErrNotFound" && len(valueTyp.Docs) != 1 {
+			t.Fatalf("变量%s注释数量不符合预期：%d", valueTyp.Name, len(valueTyp.Docs))
+		}
+		if valueTyp.Name == "StartErr" && (valueTyp.Value != "" || valueTyp.TupleIndex != 1 || valueTyp.TupleLen != 2) {
+			t.Fatalf("变量%s的多返回值表达式不符合预期：%s %d", valueTyp.Name, valueTyp.Value, valueTyp.TupleIndex)
+		}
+	}
+}
+
+func TestParseEnum(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./data/enum", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+
+	expectEnums := map[string]map[string]string{
+		"GenderID": {"GenderUnknown": "0", "GenderMale": "1", "GenderFemale": "2"},
+		"Perm":     {"PermRead": "1", "PermWrite": "2", "PermAdmin": "8", "PermAll": "11"},
+		"Platform": {"PlatformIOS": `"ios"`, "PlatformAndroid": `"android"`, "PlatformHost": ""},
+	}
+	if len(curPkgType.Enums) != len(expectEnums) {
+		t.Fatalf("枚举数量不符合预期：%d", len(curPkgType.Enums))
+	}
+	for _, enumTyp := range curPkgType.Enums {
+		expectMembers, ok := expectEnums[enumTyp.Name]
+		if !ok {
+			t.Fatalf("枚举名称不符合预期：%s", enumTyp.Name)
+		}
+		if len(enumTyp.Members) != len(expectMembers) {
+			t.Fatalf("枚举%s的成员数量不符合预期：%d", enumTyp.Name, len(enumTyp.Members))
+		}
+		for _, member := range enumTyp.Members {
+			if member.GetConstValue() != expectMembers[member.Name] {
+				t.Fatalf("枚举值%s不符合预期：%s", member.Name, member.GetConstValue())
+			}
+		}
+	}
+
+	genderTyp := curPkgType.Enums[0]
+	if genderTyp.Name != "GenderID" || genderTyp.Type.GetDecl() != "int8" {
+		t.Fatalf("枚举基础类型不符合预期：%s %s", genderTyp.Name, genderTyp.Type.GetDecl())
+	}
+	if member, _ := genderTyp.GetMember("GenderUnknown"); len(member.Docs) != 1 {
+		t.Fatalf("枚举值注释不符合预期：%v", member.Docs)
+	}
+	if member, _ := genderTyp.GetMember("GenderMale"); len(member.Comments) != 1 || member.Comments[0] != "// 男" {
+		t.Fatalf("枚举值行尾注释不符合预期：%v", member.Comments)
+	}
+	platformTyp := curPkgType.Enums[2]
+	if platformTyp.Name != "Platform" || !strings.Contains(platformTyp.GetDecl(), "\tPlatformHost Platform = Platform(runtime.GOOS)\n") {
+		t.Fatalf("无法求值的枚举值声明不符合预期：%s", platformTyp.GetDecl())
+	}
+}
+
+func TestParseDefined(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./data/defined", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+
+	expectDefineds := map[string]string{
+		"IDs":          "type IDs []int64\n",
+		"UUID":         "type UUID [16]byte\n",
+		"Hash":         "type Hash [HashSize]byte\n",
+		"Buffer":       "type Buffer [2 * HashSize]byte\n",
+		"Handler":      "type Handler func(ctx context.Context) error\n",
+		"Labels":       "type Labels map[string]string\n",
+		"Events":       "type Events chan int\n",
+		"EventSource":  "type EventSource <-chan int\n",
+		"JobSink":      "type JobSink chan<- string\n",
+		"EventSources": "type EventSources chan (<-chan int)\n",
+		"Status":       "type Status int\n",
+		"Timeout":      "type Timeout time.Duration\n",
+		"List[T any]":  "type List[T any] []T\n",
+		"RC":           "type RC ReadCloser\n",
+	}
+	expectMethods := map[string]string{"IDs": "Len", "Status": "String,Set", "List[T any]": "Push"}
+	if len(curPkgType.Defineds) != len(expectDefineds) {
+		t.Fatalf("具名类型数量不符合预期：%d", len(curPkgType.Defineds))
+	}
+	for _, definedTyp := range curPkgType.Defineds {
+		if definedTyp.PackageType != curPkgType {
+			t.Fatalf("具名类型引用的包类型不是预计的包类型")
+		}
+		if definedTyp.GetDecl() != expectDefineds[definedTyp.GetDeclName()] {
+			t.Fatalf("具名类型%s声明不符合预期：%s", definedTyp.GetDeclName(), definedTyp.GetDecl())
+		}
+		expMethod, ok := expectMethods[definedTyp.GetDeclName()]
+		if !ok {
+			if len(definedTyp.Methods) != 0 {
+				t.Fatalf("具名类型%s方法数量不符合预期：%d", definedTyp.GetDeclName(), len(definedTyp.Methods))
+			}
+			continue
+		}
+		methodNames := make([]string, 0, len(definedTyp.Methods))
+		for _, methodTyp := range definedTyp.Methods {
+			methodNames = append(methodNames, methodTyp.Name)
+		}
+		if strings.Join(methodNames, ",") != expMethod {
+			t.Fatalf("具名类型%s方法不符合预期：%d", definedTyp.GetDeclName(), len(definedTyp.Methods))
+		}
+	}
+	if handlerTyp, _ := curPkgType.GetDefinedType("Handler"); len(handlerTyp.Docs) != 1 {
+		t.Fatalf("具名类型%s注释数量不符合预期：%d", handlerTyp.Name, len(handlerTyp.Docs))
+	}
+
+	hooksTyp, _ := curPkgType.GetStructType("Hooks")
+	expectHooks := map[string]string{
+		"OnChange": "OnChange func(old *Status, new *Status) error",
+		"Format":   "Format func(format string, args ...any) (n int, err error)",
+		"OnClose":  "OnClose func()",
+	}
+	for _, field := range hooksTyp.Fields {
+		if field.FieldType.GetDecl() != expectHooks[field.Name] {
+			t.Fatalf("字段%s的声明不符合预期：%s", field.Name, field.FieldType.GetDecl())
+		}
+	}
+	formatTyp, _ := hooksTyp.AsType().FieldByName("Format")
+	if funcTyp := curPkgType.TypeOf(formatTyp.Type); !funcTyp.IsVariadic() || funcTyp.NumIn() != 2 || funcTyp.NumOut() != 2 {
+		t.Fatalf("字段Format的函数签名不符合预期：%s", funcTyp.String())
+	}
+
+	expectChanDirs := map[string]aster.ChanDir{"Events": aster.BothDir, "EventSource": aster.RecvDir, "JobSink": aster.SendDir}
+	for name, expDir := range expectChanDirs {
+		definedTyp, _ := curPkgType.GetDefinedType(name)
+		if definedTyp.Type.ChanDir != expDir || definedTyp.AsType().ChanDir() != expDir {
+			t.Fatalf("具名类型%s的通道方向不符合预期：%s", name, definedTyp.Type.ChanDir)
+		}
+	}
+
+	expectKinds := map[string]aster.Kind{"IDs": aster.Slice, "UUID": aster.Array, "Hash": aster.Array, "Buffer": aster.Array}
+	expectLens := map[string]int64{"UUID": 16, "Hash": 32, "Buffer": 64}
+	for name, expKind := range expectKinds {
+		definedTyp, _ := curPkgType.GetDefinedType(name)
+		if definedTyp.Type.Kind != expKind {
+			t.Fatalf("具名类型%s的Kind不符合预期：%d", name, definedTyp.Type.Kind)
+		}
+		if expKind == aster.Array && definedTyp.Type.LenValue != expectLens[name] {
+			t.Fatalf("具名类型%s的数组长度不符合预期：%d", name, definedTyp.Type.LenValue)
+		}
+		if expKind == aster.Array && definedTyp.AsType().Len() != int(expectLens[name]) {
+			t.Fatalf("具名类型%s的Len()不符合预期：%d", name, definedTyp.AsType().Len())
+		}
+	}
+}
+
+func TestParseMethodReceiver(t *testing.T) {
+	curPkgType, err := aster.ParseFile("./testdata/receiver/receiver.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(curPkgType.Defineds) != 2 || len(curPkgType.Defineds[0].Methods) != 2 {
+		t.Fatalf("具名类型的方法没有被正确归类")
+	}
+	if len(curPkgType.Diagnostics) != 2 {
+		t.Fatalf("诊断信息数量不符合预期：%v", curPkgType.Diagnostics)
+	}
+	expectDiagnostics := []struct {
+		line    int
+		message string
+	}{
+		{23, "method Close has invalid receiver: Reader is an interface type"},
+		{26, "method Seconds has unresolved receiver: Duration is not declared in package receiver"},
+	}
+	for i, expDiagnostic := range expectDiagnostics {
+		diagnostic := curPkgType.Diagnostics[i]
+		if diagnostic.Pos.Line != expDiagnostic.line || diagnostic.Message != expDiagnostic.message {
+			t.Fatalf("诊断信息不符合预期：%s", diagnostic)
+		}
+	}
+}
+
+func TestParseAlias(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./data/enum", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+
+	statusTyp, ok := curPkgType.GetDefinedType("StatusID")
+	if !ok {
+		t.Fatalf("没有找到别名StatusID")
+	}
+	if !statusTyp.IsAlias || statusTyp.GetAliasTarget().GetDecl() != "int8" {
+		t.Fatalf("别名StatusID解析不符合预期：%s", statusTyp.GetDecl())
+	}
+	if statusTyp.GetDecl() != "type StatusID = int8\n" {
+		t.Fatalf("别名StatusID的声明不符合预期：%s", statusTyp.GetDecl())
+	}
+	resolvedTyp := curPkgType.ResolveAlias(&aster.TypeType{Kind: aster.Ident, Name: "StatusID"})
+	if resolvedTyp.GetDecl() != "int8" {
+		t.Fatalf("别名StatusID指向的类型不符合预期：%s", resolvedTyp.GetDecl())
+	}
+
+	genderTyp, ok := curPkgType.GetDefinedType("GenderID")
+	if !ok || genderTyp.IsAlias || genderTyp.GetAliasTarget() != nil {
+		t.Fatalf("GenderID不应该是别名")
+	}
+}
+
+func TestReflectType(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./data", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+
+	likeTyp, ok := curPkgType.GetStructType("Like")
+	if !ok {
+		t.Fatalf("没有找到结构体Like")
+	}
+	var typ aster.Type = likeTyp.AsType()
+	if typ.Kind() != aster.Struct || typ.Name() != "Like" || typ.NumField() != 8 {
+		t.Fatalf("结构体Like的Type不符合预期：%s %d %d", typ.Name(), typ.Kind(), typ.NumField())
+	}
+	// TableName的接受者是*Like，不在Like的方法集中
+	if typ.NumMethod() != 0 {
+		t.Fatalf("结构体Like的方法不符合预期：%d", typ.NumMethod())
+	}
+	likePtrTyp := curPkgType.TypeOf(&aster.TypeType{Kind: aster.Star, Elem: &aster.TypeType{Kind: aster.Ident, Name: "Like"}})
+	if likePtrTyp.NumMethod() != 1 || likePtrTyp.Method(0).Name != "TableName" {
+		t.Fatalf("*Like的方法不符合预期：%d", likePtrTyp.NumMethod())
+	}
+	likerField, ok := typ.FieldByName("Liker")
+	if !ok {
+		t.Fatalf("没有找到字段Liker")
+	}
+	likerTyp := curPkgType.TypeOf(likerField.Type)
+	if likerTyp.Kind() != aster.Star || likerTyp.String() != "*User" {
+		t.Fatalf("字段Liker的Type不符合预期：%s", likerTyp.String())
+	}
+	userTyp := likerTyp.Elem()
+	if userTyp.Kind() != aster.Struct || userTyp.Name() != "User" {
+		t.Fatalf("字段Liker指向的Type不符合预期：%s", userTyp.String())
+	}
+	if _, ok := userTyp.MethodByName("HEHE"); ok {
+		t.Fatalf("结构体User的方法集不应该包含接受者为*User的方法HEHE")
+	}
+	if _, ok := likerTyp.MethodByName("HEHE"); !ok {
+		t.Fatalf("*User没有找到方法HEHE")
+	}
+	if field := typ.FieldByIndex([]int{4, 2}); field.Name != "Name" {
+		t.Fatalf("FieldByIndex的结果不符合预期：%s", field.Name)
+	}
+
+	for _, funcTyp := range curPkgType.Funcs {
+		if funcTyp.Name != "World" {
+			continue
+		}
+		worldTyp := curPkgType.FuncTypeOf(funcTyp)
+		if worldTyp.Kind() != aster.Func || worldTyp.NumIn() != 2 || worldTyp.NumOut() != 0 {
+			t.Fatalf("函数World的Type不符合预期：%s", worldTyp.String())
+		}
+		if worldTyp.In(1).Elem().Name() != "User" {
+			t.Fatalf("函数World的参数不符合预期：%s", worldTyp.In(1).String())
+		}
+	}
+
+	definedPkgsTyp, err := aster.ParseDir("./data/defined", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	labelsTyp, _ := definedPkgsTyp[0].GetDefinedType("Labels")
+	if labelsTyp.AsType().Kind() != aster.Map || labelsTyp.AsType().Key().Name() != "string" {
+		t.Fatalf("具名类型Labels的Type不符合预期：%s", labelsTyp.AsType().String())
+	}
+	statusTyp, _ := definedPkgsTyp[0].GetDefinedType("Status")
+	if statusTyp.AsType().NumMethod() != 1 || statusTyp.AsType().Method(0).Name != "String" {
+		t.Fatalf("具名类型Status的方法数量不符合预期：%d", statusTyp.AsType().NumMethod())
+	}
+	statusPtrTyp := definedPkgsTyp[0].TypeOf(&aster.TypeType{Kind: aster.Star, Elem: &aster.TypeType{Kind: aster.Ident, Name: "Status"}})
+	if statusPtrTyp.NumMethod() != 2 || statusPtrTyp.Method(0).Name != "Set" || statusPtrTyp.Method(1).Name != "String" {
+		t.Fatalf("*Status的方法数量不符合预期：%d", statusPtrTyp.NumMethod())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("对结构体调用Elem()应该panic")
+		}
+	}()
+	typ.Elem()
+}
+
+func TestParseInlineType(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./data/defined", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+
+	configTyp, _ := curPkgType.GetStructType("Config")
+	expectFields := map[string]string{
+		"DB":     "DB struct{ Host string `json:\"host\"`; Port int `json:\"port\"` }",
+		"Logger": "Logger interface{ Printf(format string, args ...interface{}); Flush() error }",
+		"Empty":  "Empty struct{}",
+		"Extra":  "Extra interface{}",
+	}
+	for _, field := range configTyp.Fields {
+		if field.FieldType.GetDecl() != expectFields[field.Name] {
+			t.Fatalf("字段%s的声明不符合预期：%s", field.Name, field.FieldType.GetDecl())
+		}
+	}
+
+	dbField, _ := configTyp.AsType().FieldByName("DB")
+	dbTyp := curPkgType.TypeOf(dbField.Type)
+	if dbTyp.Kind() != aster.Struct || dbTyp.NumField() != 2 {
+		t.Fatalf("字段DB的Type不符合预期：%s", dbTyp.String())
+	}
+	if portField, ok := dbTyp.FieldByName("Port"); !ok || portField.Tag.Get("json") != "port" {
+		t.Fatalf("字段DB.Port的标签不符合预期：%s", portField.Tag)
+	}
+	if field := configTyp.AsType().FieldByIndex([]int{0, 1}); field.Name != "Port" {
+		t.Fatalf("FieldByIndex的结果不符合预期：%s", field.Name)
+	}
+
+	loggerField, _ := configTyp.AsType().FieldByName("Logger")
+	loggerTyp := curPkgType.TypeOf(loggerField.Type)
+	if loggerTyp.NumMethod() != 2 || loggerTyp.Method(0).Name != "Flush" {
+		t.Fatalf("字段Logger的方法不符合预期：%s", loggerTyp.String())
+	}
+
+	numberTyp, _ := curPkgType.GetInterfaceType("Number")
+	if len(numberTyp.Embeds) != 1 || numberTyp.Embeds[0].Kind != aster.Union || numberTyp.Embeds[0].GetDecl() != "~int | ~int64 | float64" {
+		t.Fatalf("接口Number的类型约束不符合预期：%s", numberTyp.String())
+	}
+
+	// 方法集包括嵌入的接口中的方法，同名的方法只保留一个
+	readCloserTyp, _ := curPkgType.GetInterfaceType("ReadCloser")
+	for _, typ := range []aster.Type{readCloserTyp.AsType(), curPkgType.TypeOf(&aster.TypeType{Kind: aster.Ident, Name: "RC"})} {
+		if typ.NumMethod() != 2 || typ.Method(0).Name != "Close" || typ.Method(1).Name != "Read" {
+			t.Fatalf("%s的方法不符合预期：%d", typ.String(), typ.NumMethod())
+		}
+		if _, ok := typ.MethodByName("Read"); !ok {
+			t.Fatalf("%s没有找到嵌入的接口中的方法Read", typ.String())
+		}
+	}
+	inlineTyp := curPkgType.TypeOf(&aster.TypeType{Kind: aster.Interface, Embeds: []*aster.TypeType{{Kind: aster.Ident, Name: "Reader"}}})
+	if inlineTyp.NumMethod() != 1 || inlineTyp.Method(0).Name != "Read" {
+		t.Fatalf("匿名接口的方法不符合预期：%d", inlineTyp.NumMethod())
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./data", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+
+	likeTyp, _ := curPkgType.GetStructType("Like")
+	statusField, _ := likeTyp.AsType().FieldByName("Status")
+	if statusField.Type.Kind != aster.Selector || statusField.Type.PkgName != "eu" || statusField.Type.Name != "StatusID" {
+		t.Fatalf("字段Status的类型不符合预期：%s %s", statusField.Type.PkgName, statusField.Type.Name)
+	}
+	if statusField.Type.GetDecl() != "eu.StatusID" {
+		t.Fatalf("字段Status的类型声明不符合预期：%s", statusField.Type.GetDecl())
+	}
+	if statusField.Type.Import == nil || statusField.Type.Import.Alias != "eu" {
+		t.Fatalf("字段Status的类型没有关联到对应的import")
+	}
+
+	// 非标识符的包表达式应该返回错误而不是panic
+	astExpr, err := parser.ParseExpr("a.b.C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := aster.NewTypeType(astExpr); err == nil {
+		t.Fatalf("非标识符的包表达式应该返回错误")
+	}
+}
+
+func TestResolveImportPath(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./data/imports", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+
+	const enumPath = "github.com/szyhf/go-aster/test/data/enum"
+	expectPkgPaths := map[string]map[string]string{
+		"Alias": {"Status": enumPath},
+		"Plain": {"Gender": enumPath, "Type": "github.com/szyhf/go-aster"},
+		"Dot":   {"Platform": enumPath, "Plain": ""},
+	}
+	for structName, expectFields := range expectPkgPaths {
+		structTyp, ok := curPkgType.GetStructType(structName)
+		if !ok {
+			t.Fatalf("没有找到结构体%s", structName)
+		}
+		for fieldName, expPkgPath := range expectFields {
+			field, _ := structTyp.AsType().FieldByName(fieldName)
+			fieldTyp := field.Type
+			if fieldTyp.Kind == aster.Star {
+				fieldTyp = fieldTyp.Elem
+			}
+			if fieldTyp.PkgPath != expPkgPath {
+				t.Fatalf("字段%s.%s的包路径不符合预期：%s", structName, fieldName, fieldTyp.PkgPath)
+			}
+		}
+	}
+
+	// 路径`example.com/x/y`中声明的是`package z`，只有一个无法匹配的限定符和import时认为两者对应
+	src := "package source\n\nimport (\n\t\"fmt\"\n\t\"example.com/x/y\"\n)\n\ntype Job struct {\n\tA z.T\n\tB fmt.Stringer\n}\n"
+	srcPkgType, err := aster.ParseSource("source.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fieldTyp := srcPkgType.Structs[0].Fields[0].Type; fieldTyp.PkgPath != "example.com/x/y" || fieldTyp.Import == nil || len(srcPkgType.Diagnostics) != 0 {
+		t.Fatalf("限定符z没有关联到import：%s %v", fieldTyp.PkgPath, srcPkgType.Diagnostics)
+	}
+	if importTyp, ok := srcPkgType.Files[0].GetImport("z"); !ok || importTyp.Name != "example.com/x/y" {
+		t.Fatalf("没有通过推断出的包名找到import")
+	}
+
+	// 无法确定对应关系时记录到Diagnostics中
+	src = "package source\n\nimport (\n\t\"example.com/a/b\"\n\t\"example.com/x/y\"\n)\n\ntype Job struct {\n\tA z.T\n\tB w.T\n}\n"
+	srcPkgType, err = aster.ParseSource("source.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srcPkgType.Structs[0].Fields[0].Type.PkgPath != "" || len(srcPkgType.Diagnostics) != 2 ||
+		srcPkgType.Diagnostics[0].Pos.Line != 10 || srcPkgType.Diagnostics[1].Pos.Line != 9 {
+		t.Fatalf("无法匹配的限定符没有被记录：%v", srcPkgType.Diagnostics)
+	}
+
+	// 泛型接口的类型参数不是`.`导入的包中的类型
+	src = "package source\n\nimport . \"strings\"\n\ntype Getter[T any] interface {\n\tGet() T\n\tReader() *Reader\n}\n"
+	srcPkgType, err = aster.ParseSource("source.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	getterTyp := srcPkgType.Interfaces[0]
+	if len(getterTyp.TypeParams) != 1 || getterTyp.GetDeclName() != "Getter[T any]" {
+		t.Fatalf("泛型接口的类型参数不符合预期：%s", getterTyp.GetDeclName())
+	}
+	if resultTyp := getterTyp.Funcs[0].Results[0].Type; resultTyp.PkgPath != "" {
+		t.Fatalf("类型参数T不应该关联到`.`导入的包：%s", resultTyp.PkgPath)
+	}
+	if resultTyp := getterTyp.Funcs[1].Results[0].Type.Elem; resultTyp.PkgPath != "strings" {
+		t.Fatalf("Reader应该关联到`.`导入的包：%s", resultTyp.PkgPath)
+	}
+}
+
+func TestParseFileImports(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./data/imports", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+
+	if len(curPkgType.Files) != 4 {
+		t.Fatalf("文件数量不符合预期：%d", len(curPkgType.Files))
+	}
+	expectImports := map[string][]string{
+		"alias.go": {`eu "github.com/szyhf/go-aster/test/data/enum"`},
+		"plain.go": {`"github.com/szyhf/go-aster"`, `"github.com/szyhf/go-aster/test/data/enum"`},
+		"dot.go":   {`. "github.com/szyhf/go-aster/test/data/enum"`},
+		"blank.go": {`_ "embed"`},
+	}
+	for _, fileTyp := range curPkgType.Files {
+		if fileTyp.PackageType != curPkgType {
+			t.Fatalf("文件引用的包类型不是预计的包类型")
+		}
+		expImports := expectImports[filepath.Base(fileTyp.Name)]
+		if len(fileTyp.Imports) != len(expImports) {
+			t.Fatalf("文件%s的import数量不符合预期：%d", fileTyp.Name, len(fileTyp.Imports))
+		}
+		for i, importTyp := range fileTyp.Imports {
+			if importTyp.GetDecl() != expImports[i] {
+				t.Fatalf("文件%s的import不符合预期：%s", fileTyp.Name, importTyp.GetDecl())
+			}
+		}
+		if filepath.Base(fileTyp.Name) == "blank.go" {
+			blankImport := fileTyp.Imports[0]
+			if !blankImport.IsBlank() || len(blankImport.Docs) != 1 || len(blankImport.Comments) != 1 {
+				t.Fatalf("匿名导入解析不符合预期：%+v", blankImport)
+			}
+		}
+		if filepath.Base(fileTyp.Name) == "dot.go" && !fileTyp.Imports[0].IsDot() {
+			t.Fatalf("dot导入解析不符合预期：%+v", fileTyp.Imports[0])
+		}
+	}
+
+	// 同一个路径以不同的别名导入时，包级别的并集会保留多个
+	if len(curPkgType.Imports) != 5 {
+		t.Fatalf("包的import数量不符合预期：%d", len(curPkgType.Imports))
+	}
+}
+
+func TestParseFileInfo(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./data/file", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+
+	for _, fileTyp := range curPkgType.Files {
+		switch filepath.Base(fileTyp.Name) {
+		case "doc.go":
+			if fileTyp.BuildConstraint != "go1.18" {
+				t.Fatalf("文件%s的构建约束不符合预期：%s", fileTyp.Name, fileTyp.BuildConstraint)
+			}
+			if len(fileTyp.Headers) != 3 || fileTyp.Headers[0] != "// Copyright 2022 szyhf. All rights reserved." {
+				t.Fatalf("文件%s的头部注释不符合预期：%v", fileTyp.Name, fileTyp.Headers)
+			}
+			if len(fileTyp.Docs) != 1 || fileTyp.Docs[0] != "// Package file 用于测试文件级别的信息" {
+				t.Fatalf("文件%s的包注释不符合预期：%v", fileTyp.Name, fileTyp.Docs)
+			}
+			if len(fileTyp.Structs) != 1 || fileTyp.Structs[0].Name != "Doc" {
+				t.Fatalf("文件%s声明的结构体不符合预期", fileTyp.Name)
+			}
+			if len(fileTyp.Funcs) != 1 || len(fileTyp.Methods) != 1 || len(fileTyp.Interfaces) != 0 {
+				t.Fatalf("文件%s声明的函数不符合预期", fileTyp.Name)
+			}
+		case "plain.go":
+			if fileTyp.BuildConstraint != "" || len(fileTyp.Headers) != 0 || len(fileTyp.Docs) != 0 {
+				t.Fatalf("文件%s的注释不符合预期", fileTyp.Name)
+			}
+			if len(fileTyp.Interfaces) != 1 || len(fileTyp.Consts) != 1 || len(fileTyp.Structs) != 0 {
+				t.Fatalf("文件%s声明的内容不符合预期", fileTyp.Name)
+			}
+		default:
+			t.Fatalf("文件名不符合预期：%s", fileTyp.Name)
+		}
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./data", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+	if curPkgType.FileSet == nil {
+		t.Fatalf("没有保留解析时使用的FileSet")
+	}
+
+	var likeType *aster.StructType
+	for _, structType := range curPkgType.Structs {
+		if structType.Name == "Like" {
+			likeType = structType
+		}
+	}
+	if likeType == nil {
+		t.Fatalf("没有找到结构体Like")
+	}
+	if filepath.Base(likeType.Pos.Filename) != "model.go" || likeType.Pos.Line != 8 || likeType.Pos.Column != 6 {
+		t.Fatalf("结构体的位置不符合预期：%s", likeType.Pos)
+	}
+	if likeType.Pos.EndLine != 17 {
+		t.Fatalf("结构体的结束位置不符合预期：%d", likeType.Pos.EndLine)
+	}
+
+	statusField := likeType.Fields[1]
+	if statusField.Pos.Line != 10 || statusField.Pos.Column != 2 {
+		t.Fatalf("字段%s的位置不符合预期：%s", statusField.Name, statusField.Pos)
+	}
+	if statusField.Type.Pos.Line != 10 || statusField.Type.Pos.Column != 18 {
+		t.Fatalf("字段%s的类型的位置不符合预期：%s", statusField.Name, statusField.Type.Pos)
+	}
+
+	if len(likeType.Methods) != 1 || likeType.Methods[0].Pos.Line != 19 {
+		t.Fatalf("方法的位置不符合预期")
+	}
+	if !curPkgType.Imports[0].Pos.IsValid() {
+		t.Fatalf("import的位置无效")
+	}
+
+	receiverPkgType, err := aster.ParseFile("./testdata/receiver/receiver.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, diagnostic := range receiverPkgType.Diagnostics {
+		if !diagnostic.Pos.IsValid() {
+			t.Fatalf("诊断信息没有位置：%s", diagnostic)
+		}
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	var lastOutput string
+	for i := 0; i < 5; i++ {
+		pkgsTyp, err := aster.ParseDir("./data/imports", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		curPkgType := pkgsTyp[0]
+		output := curPkgType.String()
+		for j, fileTyp := range curPkgType.Files {
+			if j > 0 && curPkgType.Files[j-1].Name > fileTyp.Name {
+				t.Fatalf("文件没有按路径排序：%s, %s", curPkgType.Files[j-1].Name, fileTyp.Name)
+			}
+			output += fileTyp.Name + fileTyp.GetImportsDecl()
+		}
+		if i > 0 && output != lastOutput {
+			t.Fatalf("多次解析的结果不一致")
+		}
+		lastOutput = output
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./testdata/options", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgsTyp[0].Files) != 6 || len(pkgsTyp[0].Consts) != 3 {
+		t.Fatalf("默认选项下应该解析所有文件：%d", len(pkgsTyp[0].Files))
+	}
+
+	fSet := token.NewFileSet()
+	pkgsTyp, err = aster.ParseDir("./testdata/options", nil, &aster.ParseOptions{
+		SkipComments:   true,
+		SkipTests:      true,
+		GOOS:           "windows",
+		GOARCH:         "amd64",
+		SkipFuncBodies: true,
+		FileSet:        fSet,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+	if curPkgType.FileSet != fSet {
+		t.Fatalf("没有使用传入的FileSet")
+	}
+	if len(curPkgType.Files) != 2 || len(curPkgType.Structs) != 1 || len(curPkgType.Funcs) != 1 {
+		t.Fatalf("文件没有按选项过滤：%d", len(curPkgType.Files))
+	}
+	if curPkgType.Consts[0].Value != "`\\\\.\\pipe\\mysql`" {
+		t.Fatalf("没有选择windows下的文件：%s", curPkgType.Consts[0].Value)
+	}
+	if len(curPkgType.Structs[0].Docs) != 0 {
+		t.Fatalf("注释没有被丢弃")
+	}
+	if curPkgType.Funcs[0].GetASTBlockSTMT() != nil {
+		t.Fatalf("函数体没有被丢弃")
+	}
+
+	pkgsTyp, err = aster.ParseDir("./testdata/options", nil, &aster.ParseOptions{
+		SkipTests: true,
+		BuildTags: []string{"debug"},
+		GOOS:      "linux",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgsTyp[0].Structs) != 2 || len(pkgsTyp[0].Structs[1].Docs) != 1 {
+		t.Fatalf("构建标签没有生效：%d", len(pkgsTyp[0].Structs))
+	}
+}
+
+func TestParseFileLikeDir(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./data", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filePkgType, err := aster.ParseFile("./data/model.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filePkgType.Structs) == 0 || len(filePkgType.Structs[0].Docs) != 1 {
+		t.Fatalf("单文件解析时没有保留注释")
+	}
+	for _, structType := range pkgsTyp[0].Structs {
+		if structType.Name != filePkgType.Structs[0].Name {
+			continue
+		}
+		if structType.GetDecl() != filePkgType.Structs[0].GetDecl() || len(structType.Docs) != len(filePkgType.Structs[0].Docs) {
+			t.Fatalf("单文件与目录解析的结果不一致：\n%s\n%s", structType.GetDecl(), filePkgType.Structs[0].GetDecl())
+		}
+	}
+
+	if _, err := aster.ParseFile("./testdata/options/options_test.go", &aster.ParseOptions{SkipTests: true}); err == nil {
+		t.Fatalf("被选项排除的文件应该返回错误")
+	}
+}
+
+func TestParseSourceTypes(t *testing.T) {
+	testCases := []struct {
+		typeExpr string
+		kind     aster.Kind
+		decl     string
+	}{
+		{"[]int", aster.Slice, "[]int"},
+		{"[2 * N]byte", aster.Array, "[2 * N]byte"},
+		{"map[string][]*User", aster.Map, "map[string][]*User"},
+		{"<-chan chan<- int", aster.Chan, "<-chan chan<- int"},
+		{"chan (<-chan int)", aster.Chan, "chan (<-chan int)"},
+		{"func(int, ...string) (err error)", aster.Func, "func(int, ...string) (err error)"},
+		{"[]struct{ A int }", aster.Slice, "[]struct{ A int }"},
+		{"map[string]interface{ M() }", aster.Map, "map[string]interface{ M() }"},
+		{"*json.Decoder", aster.Star, "*json.Decoder"},
+		{"List[int]", aster.Ident, "List[int]"},
+	}
+	for _, testCase := range testCases {
+		src := "package source\n\nconst N = 4\n\ntype T " + testCase.typeExpr + "\n"
+		curPkgType, err := aster.ParseSource("source.go", []byte(src))
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.typeExpr, err)
+		}
+		typeType := curPkgType.Defineds[0].Type
+		if typeType.Kind != testCase.kind || typeType.GetDecl() != testCase.decl {
+			t.Fatalf("%s: 解析结果不符合预期：%d %s", testCase.typeExpr, typeType.Kind, typeType.GetDecl())
+		}
+		if typeType.Kind == aster.Array && typeType.LenValue != 8 {
+			t.Fatalf("%s: 数组长度不符合预期：%d", testCase.typeExpr, typeType.LenValue)
+		}
+		if !typeType.Pos.IsValid() || typeType.Pos.Filename != "source.go" {
+			t.Fatalf("%s: 位置不符合预期：%s", testCase.typeExpr, typeType.Pos)
+		}
+	}
+}
+
+func TestParseOverlay(t *testing.T) {
+	overlay := map[string][]byte{
+		// 替换磁盘上的文件
+		"./testdata/options/options.go": []byte("package options\n\n// 尚未保存的修改\ntype Config struct {\n\tDSN  string\n\tUser string\n}\n"),
+		// 只存在于内存中的文件
+		"testdata/options/options_extra.go": []byte("package options\n\ntype Extra struct{}\n"),
+		// 不满足构建约束的文件
+		"testdata/options/options_darwin.go": []byte("package options\n\ntype Darwin struct{}\n"),
+	}
+	pkgsTyp, err := aster.ParseDir("./testdata/options", nil, &aster.ParseOptions{
+		SkipTests: true,
+		GOOS:      "linux",
+		Overlay:   overlay,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+	if len(curPkgType.Structs) != 2 || curPkgType.Structs[0].Name != "Config" || curPkgType.Structs[1].Name != "Extra" {
+		t.Fatalf("Overlay没有生效：%d", len(curPkgType.Structs))
+	}
+	if len(curPkgType.Structs[0].Fields) != 2 || len(curPkgType.Funcs) != 0 {
+		t.Fatalf("没有使用Overlay中的内容")
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	pkgsTyp, err := aster.ParseTree(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	importPaths := make([]string, 0, len(pkgsTyp))
+	for _, pkgTyp := range pkgsTyp {
+		if pkgTyp.Name == "receiver" {
+			t.Fatalf("testdata目录没有被跳过")
+		}
+		if !filepath.IsAbs(pkgTyp.Dir) {
+			t.Fatalf("包%s的目录不是绝对路径：%s", pkgTyp.Name, pkgTyp.Dir)
+		}
+		importPaths = append(importPaths, pkgTyp.ImportPath)
+	}
+	expectImportPaths := []string{
+		"github.com/szyhf/go-aster/test",
+		"github.com/szyhf/go-aster/test/data",
+		"github.com/szyhf/go-aster/test/data/defined",
+		"github.com/szyhf/go-aster/test/data/enum",
+		"github.com/szyhf/go-aster/test/data/file",
+		"github.com/szyhf/go-aster/test/data/imports",
+		"github.com/szyhf/go-aster/test/data/value",
+	}
+	if len(importPaths) != len(expectImportPaths) {
+		t.Fatalf("包数量不符合预期：%v", importPaths)
+	}
+	for i, importPath := range importPaths {
+		if importPath != expectImportPaths[i] {
+			t.Fatalf("包的导入路径不符合预期：%s", importPath)
+		}
+	}
+}
+
+func TestParseXTest(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./testdata/options", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgsTyp) != 2 || pkgsTyp[0].Name != "options" || pkgsTyp[1].Name != "options_test" {
+		t.Fatalf("包数量不符合预期：%d", len(pkgsTyp))
+	}
+	curPkgType := pkgsTyp[0]
+	if curPkgType.XTest != pkgsTyp[1] || pkgsTyp[1].TestFor != curPkgType {
+		t.Fatalf("外部测试包没有被关联")
+	}
+	if pkgsTyp[1].ImportPath != "github.com/szyhf/go-aster/test/testdata/options_test" {
+		t.Fatalf("外部测试包的导入路径不符合预期：%s", pkgsTyp[1].ImportPath)
+	}
+	for _, fileTyp := range curPkgType.Files {
+		if fileTyp.IsTest != (filepath.Base(fileTyp.Name) == "options_test.go") {
+			t.Fatalf("文件%s是否是测试文件不符合预期", fileTyp.Name)
+		}
+	}
+	if testFuncs := curPkgType.GetTestFuncs(); len(testFuncs) != 3 {
+		t.Fatalf("测试函数数量不符合预期：%d", len(testFuncs))
+	}
+	if !curPkgType.HasTest("NewConfig") || !curPkgType.HasTest("Config.DSN") || curPkgType.HasTest("Config") {
+		t.Fatalf("测试函数匹配不符合预期")
+	}
+}
+
+func TestParseBuildConstraint(t *testing.T) {
+	pkgsTyp, err := aster.ParseDir("./testdata/options", nil, &aster.ParseOptions{GOOS: "linux", GOARCH: "amd64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+	ignoredFiles := make([]string, 0, len(curPkgType.IgnoredFiles))
+	for _, filePath := range curPkgType.IgnoredFiles {
+		ignoredFiles = append(ignoredFiles, filepath.Base(filePath))
+	}
+	if len(ignoredFiles) != 3 || ignoredFiles[0] != "options_debug.go" || ignoredFiles[1] != "options_other.go" || ignoredFiles[2] != "options_windows.go" {
+		t.Fatalf("被排除的文件不符合预期：%v", ignoredFiles)
+	}
+	if len(curPkgType.Consts) != 1 || curPkgType.Consts[0].Value != `"/var/run/mysqld.sock"` {
+		t.Fatalf("没有选择linux下的文件")
+	}
+	if len(pkgsTyp[1].IgnoredFiles) != 0 {
+		t.Fatalf("外部测试包不应该有被排除的文件")
+	}
+
+	buildCtx := build.Default
+	buildCtx.GOOS = "windows"
+	buildCtx.BuildTags = []string{"debug"}
+	pkgsTyp, err = aster.ParseDir("./testdata/options", nil, &aster.ParseOptions{BuildContext: &buildCtx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType = pkgsTyp[0]
+	if len(curPkgType.IgnoredFiles) != 2 || filepath.Base(curPkgType.IgnoredFiles[0]) != "options_linux.go" {
+		t.Fatalf("被排除的文件不符合预期：%v", curPkgType.IgnoredFiles)
+	}
+	if len(curPkgType.Structs) != 2 {
+		t.Fatalf("构建上下文没有生效")
+	}
+
+	if _, err := aster.ParseFile("./testdata/options/options_windows.go", &aster.ParseOptions{GOOS: "linux"}); err == nil {
+		t.Fatalf("不满足构建约束的文件应该返回错误")
+	}
+
+	// 所有的文件都被排除时，返回只记录了被排除的文件的空包
+	pkgsTyp, err = aster.ParseDir("./testdata/options", func(fileInfo os.FileInfo) bool {
+		return fileInfo.Name() == "options_windows.go"
+	}, &aster.ParseOptions{GOOS: "linux"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgsTyp) != 1 || pkgsTyp[0].Name != "options" || len(pkgsTyp[0].Files) != 0 ||
+		len(pkgsTyp[0].IgnoredFiles) != 1 || filepath.Base(pkgsTyp[0].IgnoredFiles[0]) != "options_windows.go" {
+		t.Fatalf("所有文件都被排除时没有记录被排除的文件")
+	}
+	if pkgsTyp[0].ImportPath != "github.com/szyhf/go-aster/test/testdata/options" {
+		t.Fatalf("空包的导入路径不符合预期：%s", pkgsTyp[0].ImportPath)
+	}
+}
+
+func TestLoadModule(t *testing.T) {
+	moduleType, err := aster.LoadModule("./data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moduleType.Main.Path != "github.com/szyhf/go-aster" || moduleType.Main.GoVersion != "1.18" {
+		t.Fatalf("go.mod的解析结果不符合预期：%s %s", moduleType.Main.Path, moduleType.Main.GoVersion)
+	}
+	curPkgType, err := aster.ParseFile("./data/model.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	statusType := curPkgType.Structs[0].Fields[1].Type
+	enumPkgType, err := moduleType.LoadPackage(statusType.PkgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enumPkgType.Name != "enum" || enumPkgType.ImportPath != "github.com/szyhf/go-aster/test/data/enum" {
+		t.Fatalf("包的解析结果不符合预期：%s %s", enumPkgType.Name, enumPkgType.ImportPath)
+	}
+	if definedType, ok := enumPkgType.GetDefinedType(statusType.Name); !ok || !definedType.IsAlias {
+		t.Fatalf("没有在包%s中找到%s", enumPkgType.Name, statusType.Name)
+	}
+	if cachedPkgType, _ := moduleType.LoadPackage(statusType.PkgPath); cachedPkgType != enumPkgType {
+		t.Fatalf("解析结果没有被缓存")
+	}
+	if _, err := moduleType.LoadPackage("example.com/unknown"); err == nil {
+		t.Fatalf("无法找到的包应该返回错误")
+	}
+}
+
+func TestLoadWorkspace(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	moduleType, err := aster.LoadModule("./testdata/workspace/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moduleType.Work == nil || len(moduleType.Modules) != 2 || moduleType.Modules[1].Path != "example.com/lib" {
+		t.Fatalf("go.work的解析结果不符合预期")
+	}
+
+	mainMod := moduleType.Main
+	if mainMod.Path != "example.com/app" || len(mainMod.Requires) != 4 || len(mainMod.Replaces) != 2 {
+		t.Fatalf("go.mod的解析结果不符合预期：%s %d %d", mainMod.Path, len(mainMod.Requires), len(mainMod.Replaces))
+	}
+	if !mainMod.Requires[0].Indirect || mainMod.Requires[1].Indirect || !mainMod.Requires[3].Indirect {
+		t.Fatalf("require的indirect不符合预期")
+	}
+	if replaceType := mainMod.Replaces[0]; replaceType.Version != "v1.2.3" || replaceType.NewPath != "example.com/legacy-fork" || replaceType.IsLocal() {
+		t.Fatalf("replace的解析结果不符合预期：%+v", replaceType)
+	}
+
+	testCases := map[string]string{
+		"example.com/app":        "app",
+		"example.com/lib":        "lib",
+		"example.com/legacy/sub": "legacy/sub", // go.work中的replace覆盖了go.mod中的
+		"example.com/remote/x":   "remote/x",
+	}
+	for importPath, expectDir := range testCases {
+		dirPath, ok := moduleType.GetPackageDir(importPath)
+		if !ok || dirPath != filepath.Join(moduleType.Work.Dir, expectDir) {
+			t.Fatalf("%s的目录不符合预期：%s", importPath, dirPath)
+		}
+	}
+	if _, ok := moduleType.GetPackageDir("example.com/tools"); ok {
+		t.Fatalf("没有replace到本地的模块不应该找到目录")
+	}
+
+	pkgsTyp, err := moduleType.LoadPackages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgsTyp) != 2 || pkgsTyp[0].ImportPath != "example.com/app" || pkgsTyp[1].ImportPath != "example.com/lib" {
+		t.Fatalf("主模块中的包不符合预期：%d", len(pkgsTyp))
+	}
+	subPkgType, err := moduleType.LoadPackage("example.com/legacy/sub")
+	if err != nil || subPkgType.Name != "sub" {
+		t.Fatalf("replace的目录中的包没有被解析：%v", err)
+	}
+
+	t.Setenv("GOWORK", "off")
+	moduleType, err = aster.LoadModule("./testdata/workspace/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moduleType.Work != nil || len(moduleType.Modules) != 1 {
+		t.Fatalf("GOWORK=off时不应该使用go.work")
+	}
+	if _, ok := moduleType.GetPackageDir("example.com/legacy"); ok {
+		t.Fatalf("go.mod中的legacy没有replace到本地")
+	}
+}
+
+func TestUniverse(t *testing.T) {
+	moduleType, err := aster.LoadModule(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgsTyp, err := aster.ParseDir("./data", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataPkgType := pkgsTyp[0]
+	universe := aster.NewUniverse(dataPkgType)
+	if _, ok := universe.GetPackage("github.com/szyhf/go-aster/test/data/enum"); ok {
+		t.Fatalf("没有设置Module时不应该加载包")
+	}
+	universe.Module = moduleType
+
+	var likeType *aster.StructType
+	for _, structType := range dataPkgType.Structs {
+		if structType.Name == "LikeGeneric" {
+			likeType = structType
+		}
+	}
+	for _, fieldType := range likeType.Fields {
+		declType, ok := universe.Resolve(dataPkgType, fieldType.Type)
+		switch fieldType.Name {
+		case "Author":
+			if !ok || declType.StructType == nil || declType.GetName() != "UserGeneric" || len(declType.TypeArgs) != 2 {
+				t.Fatalf("字段%s的类型没有被解析", fieldType.Name)
+			}
+		case "Status":
+			// StatusID是int8的别名
+			if ok {
+				t.Fatalf("内置类型不应该有声明")
+			}
+			declPkgType, typeType := universe.ResolveAlias(dataPkgType, fieldType.Type)
+			if declPkgType.Name != "enum" || typeType.GetDecl() != "int8" {
+				t.Fatalf("字段%s的别名没有被解析：%s", fieldType.Name, typeType.GetDecl())
+			}
+		case "ID":
+			if ok {
+				t.Fatalf("内置类型不应该有声明")
+			}
+		}
+	}
+
+	importsPkgsTyp, err := aster.ParseDir("./data/imports", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	importsPkgType := importsPkgsTyp[0]
+	universe.AddPackage(importsPkgType)
+	testCases := map[string]string{
+		"Gender":   "github.com/szyhf/go-aster/test/data/enum.GenderID",
+		"Platform": "github.com/szyhf/go-aster/test/data/enum.Platform", // 点导入
+		"Plain":    "github.com/szyhf/go-aster/test/data/imports.Plain",
+		"Type":     "github.com/szyhf/go-aster.TypeType",
+	}
+	checked := 0
+	for _, structType := range importsPkgType.Structs {
+		for _, fieldType := range structType.Fields {
+			if expectName, ok := testCases[fieldType.Name]; ok {
+				declType, ok := universe.Resolve(importsPkgType, fieldType.Type)
+				if !ok || declType.GetFullName() != expectName {
+					t.Fatalf("字段%s的类型没有被解析", fieldType.Name)
+				}
+				checked++
+			}
+		}
+	}
+	// 其他包中类型的别名
+	declType, ok := universe.Resolve(importsPkgType, &aster.TypeType{Kind: aster.Ident, Name: "Gender"})
+	if !ok || declType.GetFullName() != testCases["Gender"] || declType.DefinedType == nil {
+		t.Fatalf("跨包的别名没有被解析")
+	}
+	if checked != 4 || len(universe.GetPackages()) != 4 {
+		t.Fatalf("解析的数量不符合预期：%d %d", checked, len(universe.GetPackages()))
+	}
+}
+
+func TestTypeCheck(t *testing.T) {
+	// 依赖的包不通过go命令查找，无效的GOFLAGS不会影响类型检查
+	t.Setenv("GOFLAGS", "-bogusflag")
+	pkgsTyp, err := aster.ParseDir("./data", nil, &aster.ParseOptions{TypeCheck: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	curPkgType := pkgsTyp[0]
+	if curPkgType.TypesPackage == nil || curPkgType.TypesPackage.Path() != "github.com/szyhf/go-aster/test/data" {
+		t.Fatalf("没有进行类型检查")
+	}
+	if len(curPkgType.Diagnostics) != 0 {
+		t.Fatalf("类型检查不应该有错误：%v", curPkgType.Diagnostics)
+	}
+
+	for _, structType := range curPkgType.Structs {
+		if structType.Name != "LikeGeneric" {
+			continue
+		}
+		for _, fieldType := range structType.Fields {
+			switch fieldType.Name {
+			case "Status":
+				// StatusID是int8的别名
+				if basicType, ok := fieldType.TypesType.Underlying().(*types.Basic); !ok || basicType.Kind() != types.Int8 {
+					t.Fatalf("字段%s的类型不符合预期：%v", fieldType.Name, fieldType.TypesType)
+				}
+				if fieldType.Type.TypesObject == nil || fieldType.Type.TypesObject.Pkg().Name() != "enum" {
+					t.Fatalf("字段%s的类型没有关联到声明", fieldType.Name)
+				}
+			case "Author":
+				if fieldType.TypesType.String() != "*github.com/szyhf/go-aster/test/data.UserGeneric[K, V]" {
+					t.Fatalf("字段%s的类型不符合预期：%v", fieldType.Name, fieldType.TypesType)
+				}
+				if _, ok := fieldType.TypesObject.(*types.Var); !ok {
+					t.Fatalf("字段%s没有关联到对象", fieldType.Name)
+				}
+			}
+		}
+		if len(structType.Methods) != 1 {
+			t.Fatalf("方法数量不符合预期")
+		}
+		methodType := structType.Methods[0]
+		if _, ok := methodType.TypesObject.(*types.Func); !ok || methodType.TypesType.String() != "func() string" {
+			t.Fatalf("方法%s的类型不符合预期：%v", methodType.Name, methodType.TypesType)
+		}
+	}
+
+	src := "package source\n\nimport \"time\"\n\ntype Job struct {\n\tAt time.Time\n\tN  Unknown\n}\n"
+	srcPkgType, err := aster.ParseSource("source.go", []byte(src), &aster.ParseOptions{TypeCheck: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(srcPkgType.Diagnostics) != 1 || srcPkgType.Diagnostics[0].Pos.Line != 7 {
+		t.Fatalf("类型错误没有被记录：%v", srcPkgType.Diagnostics)
+	}
+	atType := srcPkgType.Structs[0].Fields[0].TypesType
+	if named, ok := atType.(*types.Named); !ok || named.Obj().Pkg().Path() != "time" || named.NumMethods() == 0 {
+		t.Fatalf("依赖包中的类型没有被解析：%v", atType)
+	}
+
+	// 依赖的包同样使用Overlay以及GOOS
+	depSrc := "package source\n\nimport \"github.com/szyhf/go-aster/test/testdata/options\"\n\nvar Extra options.Extra\n"
+	depPkgType, err := aster.ParseSource("source.go", []byte(depSrc), &aster.ParseOptions{
+		TypeCheck: true,
+		GOOS:      "darwin",
+		Overlay: map[string][]byte{
+			"testdata/options/options_extra.go": []byte("package options\n\ntype Extra struct{}\n"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(depPkgType.Diagnostics) != 0 {
+		t.Fatalf("依赖的包没有使用Overlay：%v", depPkgType.Diagnostics)
+	}
+
+	plainPkgsTyp, err := aster.ParseDir("./data", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plainPkgsTyp[0].TypesPackage != nil || plainPkgsTyp[0].Structs[0].Fields[0].TypesType != nil {
+		t.Fatalf("默认不应该进行类型检查")
+	}
+}
@@ -0,0 +1,10 @@
+package options
+
+// 数据库配置
+type Config struct {
+	DSN string
+}
+
+func NewConfig() *Config {
+	return &Config{DSN: defaultDSN}
+}
@@ -0,0 +1,8 @@
+//go:build debug
+
+package options
+
+// 仅在调试时启用
+type DebugConfig struct {
+	Verbose bool
+}
@@ -0,0 +1,3 @@
+package options
+
+const defaultDSN = "/var/run/mysqld.sock"
@@ -0,0 +1,5 @@
+//go:build !linux && !windows
+
+package options
+
+const defaultDSN = "127.0.0.1:3306"
@@ -0,0 +1,9 @@
+package options
+
+import "testing"
+
+func TestNewConfig(t *testing.T) {
+	if NewConfig().DSN == "" {
+		t.Fatal("DSN为空")
+	}
+}
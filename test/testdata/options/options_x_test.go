@@ -0,0 +1,19 @@
+package options_test
+
+import (
+	"testing"
+
+	"github.com/szyhf/go-aster/test/testdata/options"
+)
+
+func TestConfig_DSN(t *testing.T) {
+	if options.NewConfig().DSN == "" {
+		t.Fatal("DSN为空")
+	}
+}
+
+func ExampleNewConfig() {
+	_ = options.NewConfig()
+}
+
+func Testing() {}
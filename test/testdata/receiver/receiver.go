@@ -0,0 +1,28 @@
+package receiver
+
+// 这个目录下的代码无法通过编译，仅用于测试解析的容错
+
+type Status int
+
+func (s Status) String() string {
+	return "status"
+}
+
+type StatusAlias = Status
+
+// 别名作为接受者时，方法属于别名指向的类型
+func (s StatusAlias) Code() int {
+	return int(s)
+}
+
+type Reader interface {
+	Read() string
+}
+
+// 接口类型不能作为接受者
+func (r Reader) Close() {}
+
+// 接受者不是当前包声明的类型
+func (d Duration) Seconds() float64 {
+	return 0
+}
@@ -0,0 +1,18 @@
+// app用于测试go.mod的解析
+module "example.com/app"
+
+go 1.18
+
+require (
+	example.com/legacy v1.2.3 // indirect
+	example.com/lib v0.0.0
+	example.com/remote v1.0.0
+)
+
+require example.com/tools v0.1.0 // indirect; used by go generate
+
+replace example.com/legacy v1.2.3 => example.com/legacy-fork v1.2.4
+
+replace example.com/remote => ../remote
+
+exclude example.com/lib v0.0.1
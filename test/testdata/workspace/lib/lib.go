@@ -0,0 +1,5 @@
+package lib
+
+type Lib struct {
+	Name string
+}
@@ -0,0 +1,79 @@
+package aster
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 递归解析root及其所有子目录中的包，与`go list ./...`一样会跳过以下目录：
+// vendor、testdata、以`.`或`_`开头的目录，以及包含go.mod的子目录（属于另一个模块）
+// 返回的包按目录排序，同一个目录中的包按包名排序
+func ParseTree(root string, opts ...*ParseOptions) ([]*PackageType, error) {
+	opt := getParseOptions(opts)
+	dirPaths := make([]string, 0, 16)
+	err := filepath.WalkDir(root, func(dirPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if dirPath != root {
+			name := entry.Name()
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(dirPath, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		dirPaths = append(dirPaths, dirPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pkgsTyp := make([]*PackageType, 0, len(dirPaths))
+	for _, dirPath := range dirPaths {
+		dirPkgsTyp, err := ParseDir(dirPath, nil, opt)
+		if err != nil {
+			return nil, err
+		}
+		pkgsTyp = append(pkgsTyp, dirPkgsTyp...)
+	}
+	return pkgsTyp, nil
+}
+
+// 记录包所在的目录，并根据最近的go.mod推断包的导入路径
+func setPackageDir(pkgsTyp []*PackageType, dirPath string) error {
+	absDirPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return err
+	}
+	importPath, err := getDirImportPath(absDirPath)
+	if err != nil {
+		return err
+	}
+	for _, pkgTyp := range pkgsTyp {
+		pkgTyp.Dir = absDirPath
+		pkgTyp.ImportPath = importPath
+		// 外部测试包（package xxx_test）与被测试的包在同一目录，以后缀区分
+		if importPath != "" && strings.HasSuffix(pkgTyp.Name, "_test") {
+			pkgTyp.ImportPath = importPath + "_test"
+		}
+	}
+	return nil
+}
+
+// 根据最近的go.mod推断目录的导入路径，找不到go.mod时返回空字符串
+func getDirImportPath(absDirPath string) (string, error) {
+	modFile, err := findModFile(absDirPath)
+	if err != nil || modFile == nil {
+		return "", err
+	}
+	importPath, _ := modFile.GetImportPath(absDirPath)
+	return importPath, nil
+}
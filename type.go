@@ -3,6 +3,9 @@ package aster
 import (
 	"fmt"
 	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
 	"strings"
 )
 
@@ -10,17 +13,65 @@ type TypeType struct {
 	Name string `json:",omitempty"`
 	Kind Kind   `json:",omitempty"`
 
+	// 以下仅Selector有效
+	// 引用其他包时的包名，形如`eu.StatusID`中的`eu`，此时Name是`StatusID`
+	PkgName string `json:",omitempty"`
+	// 完整的import路径，形如`github.com/szyhf/go-aster/test/data/enum`
+	// 对于`.`导入的包中的Ident类型也有效
+	PkgPath string `json:",omitempty"`
+	// PkgName对应的import，找不到时为nil
+	Import *ImportType `json:"-"`
+
 	Elem       *TypeType   `json:",omitempty"`
+	Key        *TypeType   `json:",omitempty"` // 仅Map有效，Name中也保留了Key的声明
 	TypeParams []*TypeType `json:",omitempty"`
+	ChanDir    ChanDir     `json:",omitempty"` // 仅Chan有效
+
+	// 以下仅Func有效，可变参数的最后一个参数的Kind是Ellipsis
+	Params  []*FieldType `json:",omitempty"`
+	Results []*FieldType `json:",omitempty"`
+
+	// 以下仅Array有效
+	// 长度的原始表达式，形如`16`、`N`、`2 * N`
+	Len string `json:",omitempty"`
+	// 长度的求值结果，无法求值时为-1（例如引用了其他包的常量）
+	LenValue int64 `json:",omitempty"`
+
+	// 仅Struct有效，匿名结构体的字段
+	Fields []*StructFieldType `json:",omitempty"`
+
+	// 以下仅Interface有效
+	// 匿名接口声明的方法
+	Methods []*FuncType `json:",omitempty"`
+	// 匿名接口中嵌入的接口或者类型约束，形如`io.Reader`、`~int | ~string`
+	Embeds []*TypeType `json:",omitempty"`
+
+	// 仅Union有效，类型约束中以`|`连接的各项
+	Terms []*TypeType `json:",omitempty"`
+
+	Pos Position `json:",omitempty"`
+
+	// 只有设置了ParseOptions.TypeCheck时才有值，TypesObject仅在引用了具名类型时有值
+	TypesType   types.Type   `json:"-"`
+	TypesObject types.Object `json:"-"`
+
+	astExpr ast.Expr
+	astLen  ast.Expr
 }
 
 func NewTypeType(astExpr ast.Expr) (typeType *TypeType, err error) {
-	typeType = &TypeType{}
+	typeType = &TypeType{astExpr: astExpr}
 	switch exprType := astExpr.(type) {
 	case *ast.SelectorExpr:
 		// 引用其他包
+		pkgIdent, ok := exprType.X.(*ast.Ident)
+		if !ok {
+			err = fmt.Errorf("NewTypeType()未处理的*ast.SelectorExpr.X.(type)=%T: %s", exprType.X, types.ExprString(exprType))
+			return
+		}
 		typeType.Kind = Selector
-		typeType.Name = exprType.X.(*ast.Ident).Name + "." + exprType.Sel.Name
+		typeType.PkgName = pkgIdent.Name
+		typeType.Name = exprType.Sel.Name
 		return
 	case *ast.Ident:
 		typeType.Kind = Ident
@@ -29,10 +80,43 @@ func NewTypeType(astExpr ast.Expr) (typeType *TypeType, err error) {
 	case *ast.StructType:
 		typeType.Kind = Struct
 		typeType.Name = "struct{}"
+		if exprType.Fields != nil {
+			typeType.Fields = make([]*StructFieldType, 0, exprType.Fields.NumFields())
+			for _, astField := range exprType.Fields.List {
+				var fieldTypes []*StructFieldType
+				fieldTypes, err = NewStructFieldType(astField)
+				if err != nil {
+					return
+				}
+				typeType.Fields = append(typeType.Fields, fieldTypes...)
+			}
+		}
 		return
 	case *ast.FuncType:
 		typeType.Kind = Func
 		typeType.Name = "" // 匿名方法 Block
+		if exprType.Params != nil {
+			typeType.Params = make([]*FieldType, 0, exprType.Params.NumFields())
+			for _, astField := range exprType.Params.List {
+				var fieldTypes []*FieldType
+				fieldTypes, err = NewFieldTypes(astField)
+				if err != nil {
+					return
+				}
+				typeType.Params = append(typeType.Params, fieldTypes...)
+			}
+		}
+		if exprType.Results != nil {
+			typeType.Results = make([]*FieldType, 0, exprType.Results.NumFields())
+			for _, astField := range exprType.Results.List {
+				var fieldTypes []*FieldType
+				fieldTypes, err = NewFieldTypes(astField)
+				if err != nil {
+					return
+				}
+				typeType.Results = append(typeType.Results, fieldTypes...)
+			}
+		}
 		return
 	case *ast.StarExpr:
 		typeType.Kind = Star
@@ -44,29 +128,98 @@ func NewTypeType(astExpr ast.Expr) (typeType *TypeType, err error) {
 		typeType.Elem, err = NewTypeType(exprType.Elt)
 		return
 	case *ast.ArrayType:
+		if exprType.Len == nil {
+			// 切片
+			typeType.Kind = Slice
+			typeType.Elem, err = NewTypeType(exprType.Elt)
+			return
+		}
 		// 数组
 		typeType.Kind = Array
+		typeType.Len = types.ExprString(exprType.Len)
+		typeType.astLen = exprType.Len
+		typeType.LenValue = -1
+		if _, ok := exprType.Len.(*ast.Ellipsis); !ok {
+			// 这里只能对字面量求值，引用了包内常量的长度在解析完整个包以后再求值
+			typeType.evalLen(nil)
+		}
 		typeType.Elem, err = NewTypeType(exprType.Elt)
 		return
 	case *ast.MapType:
 		// 字典
 		typeType.Kind = Map
-		var keyType *TypeType
-		keyType, err = NewTypeType(exprType.Key)
+		typeType.Key, err = NewTypeType(exprType.Key)
 		if err != nil {
 			return
 		}
-		typeType.Name = keyType.GetDecl()
+		typeType.Name = typeType.Key.GetDecl()
 		typeType.Elem, err = NewTypeType(exprType.Value)
 		return
 	case *ast.ChanType:
 		// 通道
 		typeType.Kind = Chan
+		switch exprType.Dir {
+		case ast.SEND:
+			typeType.ChanDir = SendDir
+		case ast.RECV:
+			typeType.ChanDir = RecvDir
+		default:
+			typeType.ChanDir = BothDir
+		}
 		typeType.Elem, err = NewTypeType(exprType.Value)
 		return
 	case *ast.InterfaceType:
 		typeType.Kind = Interface
 		typeType.Name = "interface{...}"
+		if exprType.Methods != nil {
+			for _, astField := range exprType.Methods.List {
+				if astFuncType, ok := astField.Type.(*ast.FuncType); ok {
+					var funcType *FuncType
+					funcType, err = NewFuncTypeByASTField(astField, astFuncType)
+					if err != nil {
+						return
+					}
+					typeType.Methods = append(typeType.Methods, funcType)
+					continue
+				}
+				var embedType *TypeType
+				embedType, err = NewTypeType(astField.Type)
+				if err != nil {
+					return
+				}
+				typeType.Embeds = append(typeType.Embeds, embedType)
+			}
+		}
+		return
+	case *ast.UnaryExpr:
+		// 类型约束中的`~int`
+		if exprType.Op != token.TILDE {
+			err = fmt.Errorf("NewTypeType()未处理的*ast.UnaryExpr: %s", exprType.Op)
+			return
+		}
+		typeType.Kind = Tilde
+		typeType.Elem, err = NewTypeType(exprType.X)
+		return
+	case *ast.BinaryExpr:
+		// 类型约束中的`int | string`
+		if exprType.Op != token.OR {
+			err = fmt.Errorf("NewTypeType()未处理的*ast.BinaryExpr: %s", exprType.Op)
+			return
+		}
+		typeType.Kind = Union
+		for _, astTerm := range []ast.Expr{exprType.X, exprType.Y} {
+			var termType *TypeType
+			termType, err = NewTypeType(astTerm)
+			if err != nil {
+				return
+			}
+			if termType.Kind == Union {
+				// `a | b | c`会被解析为`(a | b) | c`，这里展开为同一层级
+				typeType.Terms = append(typeType.Terms, termType.Terms...)
+			} else {
+				typeType.Terms = append(typeType.Terms, termType)
+			}
+		}
 		return
 	case *ast.IndexExpr:
 		// 附带有1个泛型参数的S[X1 Y1]或者S[X1]结构的描述，目前是用于描述泛型的`类型形参`或者`类型实参`
@@ -135,15 +288,115 @@ func (this *TypeType) GetDecl() string {
 		return fmt.Sprintf("*%s%s", this.Elem.GetDecl(), this.getTypeParamsString())
 	case Map:
 		return fmt.Sprintf("map[%s%s]%s", this.Name, this.getTypeParamsString(), this.Elem.GetDecl())
-	case Array:
+	case Slice:
 		return fmt.Sprintf("[]%s", this.Elem.GetDecl())
+	case Array:
+		return fmt.Sprintf("[%s]%s", this.Len, this.Elem.GetDecl())
 	case Ellipsis:
 		return fmt.Sprintf("...%s", this.Elem.GetDecl())
 	case Chan:
-		return fmt.Sprintf("chan %s", this.Elem.GetDecl())
+		if this.ChanDir == BothDir && this.Elem.Kind == Chan && this.Elem.ChanDir == RecvDir {
+			// `chan <-chan T`会被解析为`chan<- chan T`，需要加括号
+			return fmt.Sprintf("chan (%s)", this.Elem.GetDecl())
+		}
+		return fmt.Sprintf("%s %s", this.ChanDir, this.Elem.GetDecl())
 	case Func:
-		return fmt.Sprintf("func(...)(...)")
+		funcType := &FuncType{Params: this.Params, Results: this.Results}
+		return funcType.GetSignature()
+	case Struct:
+		if len(this.Fields) == 0 {
+			return "struct{}"
+		}
+		decls := make([]string, 0, len(this.Fields))
+		for _, field := range this.Fields {
+			decl := field.FieldType.GetDecl()
+			if field.Tag != "" {
+				decl += " `" + string(field.Tag) + "`"
+			}
+			decls = append(decls, decl)
+		}
+		return "struct{ " + strings.Join(decls, "; ") + " }"
+	case Interface:
+		if len(this.Methods)+len(this.Embeds) == 0 {
+			return "interface{}"
+		}
+		decls := make([]string, 0, len(this.Methods)+len(this.Embeds))
+		for _, embedType := range this.Embeds {
+			decls = append(decls, embedType.GetDecl())
+		}
+		for _, funcType := range this.Methods {
+			decls = append(decls, funcType.Name+strings.TrimPrefix(funcType.GetSignature(), "func"))
+		}
+		return "interface{ " + strings.Join(decls, "; ") + " }"
+	case Tilde:
+		return "~" + this.Elem.GetDecl()
+	case Union:
+		decls := make([]string, 0, len(this.Terms))
+		for _, termType := range this.Terms {
+			decls = append(decls, termType.GetDecl())
+		}
+		return strings.Join(decls, " | ")
+	case Selector:
+		return fmt.Sprintf("%s.%s%s", this.PkgName, this.Name, this.getTypeParamsString())
 	default:
 		return fmt.Sprintf("%s%s", this.Name, this.getTypeParamsString())
 	}
 }
+
+// 解析时对应的表达式，不是通过NewTypeType创建时为nil
+func (this *TypeType) GetASTExpr() ast.Expr {
+	return this.astExpr
+}
+
+// 对数组的长度求值，evaluator为nil时只能对字面量求值
+func (this *TypeType) evalLen(evaluator *constEvaluator) {
+	if evaluator == nil {
+		evaluator = &constEvaluator{}
+	}
+	defer func() {
+		// go/constant在操作数类型不匹配时会panic，视为无法求值
+		recover()
+	}()
+	val, err := evaluator.evalExpr(this.astLen, 0)
+	if err != nil {
+		return
+	}
+	if n, ok := constant.Int64Val(constant.ToInt(val)); ok {
+		this.LenValue = n
+	}
+}
+
+// 深度优先遍历当前类型以及所有嵌套的类型，fn返回false时不再遍历其嵌套的类型
+func (this *TypeType) walk(fn func(*TypeType) bool) {
+	if this == nil || !fn(this) {
+		return
+	}
+	this.Elem.walk(fn)
+	this.Key.walk(fn)
+	for _, typeParam := range this.TypeParams {
+		typeParam.walk(fn)
+	}
+	for _, fieldType := range this.Params {
+		fieldType.Type.walk(fn)
+	}
+	for _, fieldType := range this.Results {
+		fieldType.Type.walk(fn)
+	}
+	for _, fieldType := range this.Fields {
+		fieldType.Type.walk(fn)
+	}
+	for _, funcType := range this.Methods {
+		for _, fieldType := range funcType.Params {
+			fieldType.Type.walk(fn)
+		}
+		for _, fieldType := range funcType.Results {
+			fieldType.Type.walk(fn)
+		}
+	}
+	for _, embedType := range this.Embeds {
+		embedType.walk(fn)
+	}
+	for _, termType := range this.Terms {
+		termType.walk(fn)
+	}
+}
@@ -0,0 +1,110 @@
+package aster
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// 使用go/types对包进行类型检查，并把结果关联到TypeType、FieldType、FuncType上
+// 类型错误不会中断解析，而是记录在Diagnostics中
+func (this *PackageType) typeCheck(typesImporter types.Importer) {
+	astFiles := make([]*ast.File, 0, len(this.Files))
+	for _, fileType := range this.Files {
+		astFiles = append(astFiles, fileType.astFile)
+	}
+	info := &types.Info{
+		Types:     make(map[ast.Expr]types.TypeAndValue),
+		Instances: make(map[*ast.Ident]types.Instance),
+		Defs:      make(map[*ast.Ident]types.Object),
+		Uses:      make(map[*ast.Ident]types.Object),
+	}
+	conf := &types.Config{
+		Importer: typesImporter,
+		Error: func(err error) {
+			if typesErr, ok := err.(types.Error); ok {
+				this.addDiagnostic(newPosition(this.FileSet, typesErr.Pos, typesErr.Pos), "%s", typesErr.Msg)
+				return
+			}
+			this.addDiagnostic(Position{}, "%s", err.Error())
+		},
+	}
+	pkgPath := this.ImportPath
+	if pkgPath == "" {
+		pkgPath = this.Name
+	}
+	// 错误已经通过conf.Error记录，即使有错误也会返回尽可能完整的结果
+	this.TypesPackage, _ = conf.Check(pkgPath, this.FileSet, astFiles, info)
+	this.TypesInfo = info
+
+	this.walkTypeTypes(func(typeType *TypeType) bool {
+		// 泛型的astExpr已经是`UserGeneric[K, V]`中的`UserGeneric`，指针等复合类型的TypesObject记录在Elem上
+		var typeIdent *ast.Ident
+		switch exprType := typeType.astExpr.(type) {
+		case *ast.Ident:
+			typeIdent = exprType
+		case *ast.SelectorExpr:
+			typeIdent = exprType.Sel
+		}
+		if typeIdent != nil {
+			typeType.TypesObject = info.ObjectOf(typeIdent)
+			// 泛型的实例化，例如`UserGeneric[K, V]`
+			if instance, ok := info.Instances[typeIdent]; ok {
+				typeType.TypesType = instance.Type
+				return true
+			}
+		}
+		if typeType.astExpr != nil {
+			typeType.TypesType = info.TypeOf(typeType.astExpr)
+		}
+		return true
+	})
+	this.walkFieldsAndFuncs(func(fieldType *FieldType) {
+		nameIdent := fieldType.astName
+		if nameIdent == nil || !nameIdent.Pos().IsValid() {
+			// 嵌入字段的对象记录在类型的标识符上
+			if fieldType.astField == nil {
+				return
+			}
+			nameIdent = getTypeIdent(fieldType.astField.Type)
+		}
+		if obj := info.Defs[nameIdent]; obj != nil {
+			fieldType.TypesObject = obj
+			fieldType.TypesType = obj.Type()
+		} else if fieldType.Type != nil {
+			fieldType.TypesType = fieldType.Type.TypesType
+		}
+	}, func(funcType *FuncType) {
+		var nameIdent *ast.Ident
+		switch astNode := funcType.astNode.(type) {
+		case *ast.FuncDecl:
+			nameIdent = astNode.Name
+		case *ast.Field:
+			if len(astNode.Names) > 0 {
+				nameIdent = astNode.Names[0]
+			}
+		}
+		if obj := info.Defs[nameIdent]; obj != nil {
+			funcType.TypesObject = obj
+			funcType.TypesType = obj.Type()
+		}
+	})
+}
+
+// 嵌入字段的类型中表示类型名的标识符，例如`*eu.StatusID`中的`StatusID`，匿名类型返回nil
+func getTypeIdent(astExpr ast.Expr) *ast.Ident {
+	switch exprType := astExpr.(type) {
+	case *ast.Ident:
+		return exprType
+	case *ast.SelectorExpr:
+		return exprType.Sel
+	case *ast.StarExpr:
+		return getTypeIdent(exprType.X)
+	case *ast.IndexExpr:
+		return getTypeIdent(exprType.X)
+	case *ast.IndexListExpr:
+		return getTypeIdent(exprType.X)
+	case *ast.ParenExpr:
+		return getTypeIdent(exprType.X)
+	}
+	return nil
+}
@@ -1,5 +1,7 @@
 package aster
 
+import "strconv"
+
 type Kind uint8
 
 const (
@@ -19,8 +21,34 @@ const (
 
 	Field
 	StructField
+
+	// 新增的Kind追加在末尾，避免改变已有Kind的值
+	Slice
+	Union // 类型约束中的`int | string`
+	Tilde // 类型约束中的`~int`
+)
+
+// A ChanDir represents a channel type's direction.
+type ChanDir int
+
+const (
+	RecvDir ChanDir             = 1 << iota // <-chan
+	SendDir                                 // chan<-
+	BothDir = RecvDir | SendDir             // chan
 )
 
+func (d ChanDir) String() string {
+	switch d {
+	case SendDir:
+		return "chan<-"
+	case RecvDir:
+		return "<-chan"
+	case BothDir:
+		return "chan"
+	}
+	return "ChanDir" + strconv.Itoa(int(d))
+}
+
 // Static Version of refelct.Type
 type Type interface {
 	// Method returns the i'th method in the type's method set.
@@ -55,12 +83,18 @@ type Type interface {
 
 	// ChanDir returns a channel type's direction.
 	// It panics if the type's Kind is not Chan.
-	// ChanDir() ChanDir
+	ChanDir() ChanDir
 
 	// Elem returns a type's element type.
 	// It panics if the type's Kind is not Array, Chan, Map, Ptr, or Slice.
 	Elem() Type
 
+	// Len returns an array type's length.
+	// It panics if the type's Kind is not Array.
+	// It returns -1 if the length is not a constant that can be evaluated
+	// within the package.
+	Len() int
+
 	// Field returns a struct type's i'th field.
 	// It panics if the type's Kind is not Struct.
 	// It panics if i is not in the range [0, NumField()).
@@ -81,6 +115,11 @@ type Type interface {
 	// It panics if i is not in the range [0, NumIn()).
 	In(i int) Type
 
+	// IsVariadic reports whether a function type's final input parameter
+	// is a "..." parameter.
+	// It panics if the type's Kind is not Func.
+	IsVariadic() bool
+
 	// Key returns a map type's key type.
 	// It panics if the type's Kind is not Map.
 	Key() Type
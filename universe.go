@@ -0,0 +1,192 @@
+package aster
+
+import "sort"
+
+// 以导入路径为键保存多个包，用于解析包之间的类型引用，例如`eu.StatusID`
+type Universe struct {
+	// 不为空时，找不到的包会通过Module按导入路径加载
+	Module *ModuleType `json:"-"`
+
+	pkgMap map[string]*PackageType
+}
+
+// 类型引用最终指向的声明，StructType、InterfaceType、DefinedType中有且只有一个不为空
+type DeclType struct {
+	PackageType   *PackageType   `json:"-"` // 声明所在的包
+	StructType    *StructType    `json:",omitempty"`
+	InterfaceType *InterfaceType `json:",omitempty"`
+	DefinedType   *DefinedType   `json:",omitempty"` // 不会是别名，别名会被继续解析
+	// 泛型的类型实参，例如`UserGeneric[K, V]`中的`K`、`V`
+	TypeArgs []*TypeType `json:",omitempty"`
+}
+
+func NewUniverse(pkgsTyp ...*PackageType) *Universe {
+	universe := &Universe{
+		pkgMap: make(map[string]*PackageType, len(pkgsTyp)),
+	}
+	for _, pkgTyp := range pkgsTyp {
+		universe.AddPackage(pkgTyp)
+	}
+	return universe
+}
+
+// 以包的导入路径为键，没有导入路径时（例如不在任何模块中）以包名为键
+// 已经存在相同导入路径的包时会覆盖
+func (this *Universe) AddPackage(pkgTyp *PackageType) {
+	this.pkgMap[getUniverseKey(pkgTyp)] = pkgTyp
+}
+
+func getUniverseKey(pkgTyp *PackageType) string {
+	if pkgTyp.ImportPath != "" {
+		return pkgTyp.ImportPath
+	}
+	return pkgTyp.Name
+}
+
+// 根据导入路径获取包，设置了Module时会尝试加载尚未添加的包
+func (this *Universe) GetPackage(importPath string) (*PackageType, bool) {
+	if pkgTyp, ok := this.pkgMap[importPath]; ok {
+		return pkgTyp, true
+	}
+	if this.Module == nil {
+		return nil, false
+	}
+	pkgTyp, err := this.Module.LoadPackage(importPath)
+	if err != nil {
+		return nil, false
+	}
+	this.pkgMap[importPath] = pkgTyp
+	return pkgTyp, true
+}
+
+// 所有的包，按导入路径排序
+func (this *Universe) GetPackages() []*PackageType {
+	keys := make([]string, 0, len(this.pkgMap))
+	for key := range this.pkgMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pkgsTyp := make([]*PackageType, 0, len(keys))
+	for _, key := range keys {
+		pkgsTyp = append(pkgsTyp, this.pkgMap[key])
+	}
+	return pkgsTyp
+}
+
+// 沿着别名（包括其他包中声明的别名）找到最终指向的类型，以及该类型被引用时所在的包
+// 例如`type Gender = enum.GenderID`会被解析为enum包中的`GenderID`
+// 对于指针会解析其指向的类型，不是别名或者无法找到声明时返回原类型
+func (this *Universe) ResolveAlias(pkgTyp *PackageType, typeType *TypeType) (*PackageType, *TypeType) {
+	if typeType == nil {
+		return pkgTyp, nil
+	}
+	if typeType.Kind == Star {
+		elemPkgTyp, elemType := this.ResolveAlias(pkgTyp, typeType.Elem)
+		if elemType == typeType.Elem {
+			return pkgTyp, typeType
+		}
+		return elemPkgTyp, &TypeType{Kind: Star, Elem: elemType}
+	}
+	// 避免循环别名导致死循环
+	visited := make(map[*DefinedType]struct{})
+	for {
+		declPkgTyp, name, ok := this.getDeclPackage(pkgTyp, typeType)
+		if !ok {
+			break
+		}
+		definedType, ok := declPkgTyp.GetDefinedType(name)
+		if !ok || !definedType.IsAlias {
+			break
+		}
+		if _, ok := visited[definedType]; ok {
+			break
+		}
+		visited[definedType] = struct{}{}
+		pkgTyp, typeType = declPkgTyp, definedType.Type
+	}
+	return pkgTyp, typeType
+}
+
+// 找到类型引用对应的声明，会跳过指针并沿着别名继续查找
+// 内置类型、匿名类型（例如`[]int`）以及无法找到包的类型返回false
+func (this *Universe) Resolve(pkgTyp *PackageType, typeType *TypeType) (*DeclType, bool) {
+	for typeType != nil && typeType.Kind == Star {
+		typeType = typeType.Elem
+	}
+	pkgTyp, typeType = this.ResolveAlias(pkgTyp, typeType)
+	for typeType != nil && typeType.Kind == Star {
+		typeType = typeType.Elem
+	}
+	declPkgTyp, name, ok := this.getDeclPackage(pkgTyp, typeType)
+	if !ok {
+		return nil, false
+	}
+	declType := &DeclType{
+		PackageType: declPkgTyp,
+		TypeArgs:    typeType.TypeParams,
+	}
+	for _, structType := range declPkgTyp.Structs {
+		if structType.Name == name {
+			declType.StructType = structType
+			return declType, true
+		}
+	}
+	for _, interfaceType := range declPkgTyp.Interfaces {
+		if interfaceType.Name == name {
+			declType.InterfaceType = interfaceType
+			return declType, true
+		}
+	}
+	if definedType, ok := declPkgTyp.GetDefinedType(name); ok && !definedType.IsAlias {
+		declType.DefinedType = definedType
+		return declType, true
+	}
+	return nil, false
+}
+
+// 类型引用的名字以及声明所在的包
+func (this *Universe) getDeclPackage(pkgTyp *PackageType, typeType *TypeType) (*PackageType, string, bool) {
+	if typeType == nil {
+		return nil, "", false
+	}
+	switch typeType.Kind {
+	case Ident:
+		// 点导入的类型已经在linkImports中记录了所属包的路径
+		if typeType.PkgPath != "" {
+			declPkgTyp, ok := this.GetPackage(typeType.PkgPath)
+			return declPkgTyp, typeType.Name, ok
+		}
+		if pkgTyp == nil {
+			return nil, "", false
+		}
+		return pkgTyp, typeType.Name, true
+	case Selector:
+		if typeType.PkgPath == "" {
+			return nil, "", false
+		}
+		declPkgTyp, ok := this.GetPackage(typeType.PkgPath)
+		return declPkgTyp, typeType.Name, ok
+	}
+	return nil, "", false
+}
+
+// 获取声明的名字
+func (this *DeclType) GetName() string {
+	switch {
+	case this.StructType != nil:
+		return this.StructType.Name
+	case this.InterfaceType != nil:
+		return this.InterfaceType.Name
+	case this.DefinedType != nil:
+		return this.DefinedType.Name
+	}
+	return ""
+}
+
+// 形如`github.com/szyhf/go-aster/test/data/enum.GenderID`
+func (this *DeclType) GetFullName() string {
+	if this.PackageType == nil {
+		return this.GetName()
+	}
+	return getUniverseKey(this.PackageType) + "." + this.GetName()
+}
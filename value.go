@@ -0,0 +1,156 @@
+package aster
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// 用于描述一个以const或者var声明的顶级变量
+// 形如`const Name Type = Value`或者`var Name Type = Value`
+type ValueType struct {
+	PackageType *PackageType `json:"-"`
+
+	Name     string    `json:",omitempty"`
+	Type     *TypeType `json:",omitempty"` // 省略类型声明时为nil
+	Value    string    `json:",omitempty"` // 初始化表达式的原始代码，没有初始化表达式时为空
+	Docs     []Comment `json:",omitempty"`
+	Comments []Comment `json:",omitempty"` // 行尾的注释
+	IsConst  bool      `json:",omitempty"`
+
+	// 以下仅对形如`var x, y = f()`的声明有效，多个变量共用一个多返回值的表达式，此时Value为空
+	TupleValue string `json:",omitempty"` // 多返回值表达式的原始代码，形如`f()`
+	TupleIndex int    `json:",omitempty"` // 当前变量对应的返回值序号，`y`为1
+	TupleLen   int    `json:",omitempty"` // 声明的变量个数，`x, y`为2
+
+	// 以下仅对const有效
+	// 在const分组中的序号，即声明时iota的值
+	Iota int `json:",omitempty"`
+	// 常量表达式的求值结果，无法在包内求值时为nil（例如引用了其他包的常量）
+	ConstValue constant.Value `json:"-"`
+
+	Pos Position `json:",omitempty"`
+
+	// 实际用于求值的表达式，省略表达式时为分组中前一个声明的表达式
+	astValue ast.Expr
+	astName  *ast.Ident
+}
+
+// 因为语法上存在一次声明多个变量的情况，所以返回值是数组（例如`var x, y = 1, 2`）
+func (pkgType *PackageType) NewValueTypes(astGenDecl *ast.GenDecl, valueSpec *ast.ValueSpec) ([]*ValueType, error) {
+	isConst := astGenDecl.Tok == token.CONST
+	iota := 0
+	astType, astValues := valueSpec.Type, valueSpec.Values
+	if isConst {
+		// 在const分组中省略类型和表达式时，沿用前一个有表达式的声明（implicit repetition）
+		var prevSpec *ast.ValueSpec
+		for i, spec := range astGenDecl.Specs {
+			if spec == valueSpec {
+				iota = i
+				break
+			}
+			if curSpec, ok := spec.(*ast.ValueSpec); ok && len(curSpec.Values) > 0 {
+				prevSpec = curSpec
+			}
+		}
+		if len(astValues) == 0 && astType == nil && prevSpec != nil {
+			astType, astValues = prevSpec.Type, prevSpec.Values
+		}
+	}
+
+	var typeType *TypeType
+	if astType != nil {
+		var err error
+		typeType, err = NewTypeType(astType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	docs := newSpecDocs(astGenDecl, valueSpec.Doc)
+	comments := newComments(valueSpec.Comment)
+
+	valueTypes := make([]*ValueType, len(valueSpec.Names))
+	for i, astName := range valueSpec.Names {
+		valueType := &ValueType{
+			PackageType: pkgType,
+
+			Name:     astName.Name,
+			Type:     typeType,
+			Docs:     docs,
+			Comments: comments,
+			IsConst:  isConst,
+			Iota:     iota,
+			astName:  astName,
+		}
+		switch {
+		case len(astValues) == len(valueSpec.Names):
+			valueType.astValue = astValues[i]
+		case len(astValues) == 1:
+			// 形如`var x, y = f()`，多个变量共用一个多返回值的表达式，单独的变量没有对应的表达式
+			valueType.TupleValue = types.ExprString(astValues[0])
+			valueType.TupleIndex = i
+			valueType.TupleLen = len(valueSpec.Names)
+		}
+		if valueType.astValue != nil && len(valueSpec.Values) > 0 {
+			valueType.Value = types.ExprString(valueType.astValue)
+		}
+		valueTypes[i] = valueType
+	}
+	return valueTypes, nil
+}
+
+// 获取完整的声明，形如`const Name Type = Value`
+// 共用多返回值表达式的变量，其余的变量以`_`代替，形如`var _, y = f()`
+func (this *ValueType) GetDecl() string {
+	sb := strings.Builder{}
+	if this.IsConst {
+		sb.WriteString("const ")
+	} else {
+		sb.WriteString("var ")
+	}
+	if this.TupleValue != "" {
+		for i := 0; i < this.TupleLen; i++ {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			if i == this.TupleIndex {
+				sb.WriteString(this.Name)
+			} else {
+				sb.WriteString("_")
+			}
+		}
+	} else {
+		sb.WriteString(this.Name)
+	}
+	if this.Type != nil {
+		sb.WriteString(" " + this.Type.GetDecl())
+	}
+	if this.TupleValue != "" {
+		sb.WriteString(" = " + this.TupleValue)
+	} else if this.Value != "" {
+		sb.WriteString(" = " + this.Value)
+	} else if this.IsConst && this.ConstValue != nil {
+		// 省略了表达式的常量，使用求值结果
+		sb.WriteString(" = " + this.ConstValue.ExactString())
+	}
+	return sb.String()
+}
+
+// 常量的求值结果，形如`1`、`"abc"`，无法求值时返回空字符串
+func (this *ValueType) GetConstValue() string {
+	if this.ConstValue == nil {
+		return ""
+	}
+	return this.ConstValue.ExactString()
+}
+
+// 以int64的形式获取常量的求值结果，仅当结果是可以用int64表示的整数时ok为true
+func (this *ValueType) GetInt64() (int64, bool) {
+	if this.ConstValue == nil || this.ConstValue.Kind() != constant.Int {
+		return 0, false
+	}
+	return constant.Int64Val(this.ConstValue)
+}